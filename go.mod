@@ -3,13 +3,13 @@ module github.com/fsgo/bin-auto-switcher
 go 1.26.4
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/xanygo/anygo v0.0.0-20260629072412-ac1831fd8d48
 	github.com/xanygo/ext v0.0.0-20260228134916-3cc748f50bb3
 	golang.org/x/mod v0.37.0
 )
 
 require (
-	github.com/BurntSushi/toml v1.6.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.13 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
@@ -6,6 +6,7 @@ package actuator
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"sync/atomic"
@@ -31,12 +32,36 @@ func find(name string) func([]string) Actuator {
 	return all[name]
 }
 
+// Exists 判断是否有名为 name 的 Actuator，如 "inner:find-exec"
+func Exists(name string) bool {
+	return find(name) != nil
+}
+
+// outputSetter 支持设置输出的 Actuator，如 FindExec 中执行的命令
+type outputSetter interface {
+	SetOutput(stdout io.Writer, stderr io.Writer)
+}
+
+// traceSetter 支持打印执行过程日志的 Actuator
+type traceSetter interface {
+	SetTrace(trace bool)
+}
+
 type Config struct {
-	ac       Actuator
-	Name     string
-	Dir      string
-	Args     []string
-	Env      []string
+	ac   Actuator
+	Name string
+	Dir  string
+	Args []string
+	Env  []string
+
+	// Stdout、Stderr 命令的输出，可选，默认为 os.Stdout、os.Stderr
+	// 并行执行时，用于缓存每个命令的输出
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Trace 是否打印执行过程的日志，每个命令单独设置，以便并行执行
+	Trace bool
+
 	exitCode atomic.Int32
 }
 
@@ -52,12 +77,20 @@ func (r *Config) getActuator() Actuator {
 	fn := find(r.Name)
 	if fn != nil {
 		r.ac = fn(r.Args)
+		if st, ok := r.ac.(outputSetter); ok {
+			st.SetOutput(r.Stdout, r.Stderr)
+		}
+		if ts, ok := r.ac.(traceSetter); ok {
+			ts.SetTrace(r.Trace)
+		}
 		return r.ac
 	}
 
 	r.ac = &Cmd{
 		CmdName: r.Name,
 		Args:    r.Args,
+		Stdout:  r.Stdout,
+		Stderr:  r.Stderr,
 		Setup: func(cmd *exec.Cmd) {
 			cmd.Dir = r.Dir
 			if len(r.Env) > 0 {
@@ -6,6 +6,7 @@ package actuator
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"sync/atomic"
@@ -14,9 +15,14 @@ import (
 var _ Actuator = (*Cmd)(nil)
 
 type Cmd struct {
-	Setup    func(cmd *exec.Cmd)
-	CmdName  string
-	Args     []string
+	Setup   func(cmd *exec.Cmd)
+	CmdName string
+	Args    []string
+
+	// Stdout、Stderr 可选，默认为 os.Stdout、os.Stderr
+	Stdout io.Writer
+	Stderr io.Writer
+
 	exitCode atomic.Int32
 }
 
@@ -29,6 +35,12 @@ func (c *Cmd) Run(ctx context.Context) error {
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if c.Stdout != nil {
+		cmd.Stdout = c.Stdout
+	}
+	if c.Stderr != nil {
+		cmd.Stderr = c.Stderr
+	}
 	if c.Setup != nil {
 		c.Setup(cmd)
 	}
@@ -7,7 +7,6 @@ package actuator
 import (
 	"path/filepath"
 	"strings"
-	"sync/atomic"
 )
 
 func stringsTrim(ss []string) []string {
@@ -32,9 +31,6 @@ func stringsTrim(ss []string) []string {
 
 var GetRawBinName func(binName string) string
 
-// Trace 是否打印日志
-var Trace = atomic.Bool{}
-
 // WorkDir 当前目录
 var WorkDir string
 
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"os"
@@ -27,6 +28,18 @@ type FindExec struct {
 	Args     []string
 	flagName string
 	wd       string
+
+	stdout io.Writer
+	stderr io.Writer
+	trace  bool
+}
+
+func (fe *FindExec) SetOutput(stdout io.Writer, stderr io.Writer) {
+	fe.stdout, fe.stderr = stdout, stderr
+}
+
+func (fe *FindExec) SetTrace(trace bool) {
+	fe.trace = trace
 }
 
 func (fe *FindExec) Name() string {
@@ -142,7 +155,7 @@ func (fe *FindExec) findRootDir(names []string) (string, error) {
 }
 
 func (fe *FindExec) run(ctx context.Context, rootDir string, match func(fileName string) bool, cmdName string, args []string) error {
-	if Trace.Load() {
+	if fe.trace {
 		log.Println("scan from ", relPath(rootDir))
 	}
 
@@ -161,7 +174,7 @@ func (fe *FindExec) run(ctx context.Context, rootDir string, match func(fileName
 			if name == "node_modules" || name == "temp" || name == "tmp" || name == "target" ||
 				strings.HasPrefix(name, ".") ||
 				strings.HasPrefix(name, "_") {
-				if Trace.Load() {
+				if fe.trace {
 					log.Printf("dir %s skipped", xcolor.YellowString(relPath(path)))
 				}
 				return fs.SkipDir
@@ -190,12 +203,14 @@ func (fe *FindExec) run(ctx context.Context, rootDir string, match func(fileName
 		index++
 
 		rr := &Config{
-			Name: cmdName,
-			Args: args,
-			Dir:  dir,
+			Name:   cmdName,
+			Args:   args,
+			Dir:    dir,
+			Stdout: fe.stdout,
+			Stderr: fe.stderr,
 		}
 		var logs []string
-		if Trace.Load() {
+		if fe.trace {
 			s0 := xcolor.GreenString("%2d.", index)
 			rl, _ := filepath.Rel(fe.wd, dir)
 			s1 := fmt.Sprintf("Dir= %s MatchFile= %s", rl, fileName)
@@ -205,16 +220,16 @@ func (fe *FindExec) run(ctx context.Context, rootDir string, match func(fileName
 		start := time.Now()
 		e1 := rr.Run(ctx)
 		cost := time.Since(start)
-		if Trace.Load() {
+		if fe.trace {
 			logs = append(logs, "Cost=", common.CostString(cost))
 		}
 		if e1 != nil {
 			fail++
-			if Trace.Load() {
+			if fe.trace {
 				logs = append(logs, "Err=", xcolor.RedString(e1.Error()))
 			}
 		}
-		if Trace.Load() {
+		if fe.trace {
 			log.Println(strings.Join(logs, " "))
 		}
 		return fs.SkipDir
@@ -226,7 +241,7 @@ func (fe *FindExec) run(ctx context.Context, rootDir string, match func(fileName
 		return fmt.Errorf("total %d/%d tasks failed", fail, index)
 	}
 
-	if index == 0 && Trace.Load() {
+	if index == 0 && fe.trace {
 		log.Printf("file %q not found, skipped", fe.flagName)
 	}
 
@@ -21,7 +21,12 @@ import (
 var _ Actuator = (*GitAddModify)(nil)
 
 type GitAddModify struct {
-	Args []string
+	Args  []string
+	trace bool
+}
+
+func (gm *GitAddModify) SetTrace(trace bool) {
+	gm.trace = trace
 }
 
 func (gm *GitAddModify) Name() string {
@@ -142,7 +147,7 @@ func (gm *GitAddModify) Run(ctx context.Context) error {
 		}
 		start := time.Now()
 		sub := exec.CommandContext(ctx, args[0], extArgs...)
-		if Trace.Load() {
+		if gm.trace {
 			log.Println("Exec:", sub.String())
 		}
 		err1 := sub.Run()
@@ -150,13 +155,13 @@ func (gm *GitAddModify) Run(ctx context.Context) error {
 		if err1 != nil {
 			cnts["Failed"]++
 			errs = append(errs, err1)
-			if Trace.Load() {
+			if gm.trace {
 				log.Println("Exec Failed:", sub.String(), "Cost=", cost.String(), "Err=", err1.Error())
 			}
 		}
 	}
 
-	if Trace.Load() {
+	if gm.trace {
 		log.Println("git-am statistics:", cnts)
 	}
 
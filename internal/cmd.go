@@ -6,9 +6,10 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
 	"regexp"
 	"slices"
 	"time"
@@ -20,6 +21,17 @@ import (
 )
 
 type Command struct {
+	// ID 命令的唯一标识，可选
+	// 多层配置合并时，ID 相同的命令，后加载(更靠近当前目录)的会替换先加载的
+	ID string `json:",omitempty"`
+
+	// Needs 依赖的命令的 ID，可选，如 ["codegen"]
+	// 同一组(Pre 或 Post)中有命令配置了 Needs 时，所有命令按照依赖关系执行：
+	// 依赖都结束后即开始执行，没有依赖关系的命令并行执行，依赖的命令失败(且不允许失败)时跳过，
+	// 依赖的命令不匹配(Match、Cond)而未执行的，视为已完成
+	// 没有配置 Needs 的命令依赖前一个命令(除非前一个命令依赖它)，Group 相同的相邻命令并行执行
+	Needs []string `json:",omitempty"`
+
 	// Match 用于匹配执行命令的正则表达式，可选
 	// 如命令为 "git add ." 则，"add ." 会交给此正则来匹配
 	// 若不匹配，当前这组命令将不会执行
@@ -51,6 +63,85 @@ type Command struct {
 
 	// Env 执行此命令所特有的环境变量信息
 	Env []string `json:",omitempty"`
+
+	// Parallel 是否和其他 Parallel 的命令并行执行，可选，等同于 Group = "parallel"
+	Parallel bool `json:",omitempty"`
+
+	// Group 并行执行的分组，可选，同一组的命令在组中第一个命令的位置并行执行，
+	// 最大并发数为 Rule.Concurrency，各命令的输出先缓存，再按照配置的顺序输出，
+	// 全部执行完成后，若有不允许失败(AllowFail)的命令失败了，程序退出
+	Group string `json:",omitempty"`
+
+	// On 根据主命令的执行结果判断是否执行，可选，仅对 Post 有效
+	// success(默认): 主命令成功后执行；failure: 主命令失败后执行；always: 总是执行
+	// Pre 失败时主命令不执行，以失败的 Pre 命令作为主命令的结果，failure、always 的仍然执行
+	// 执行时，环境变量 BAS_EXIT_CODE 为主命令的退出码，BAS_DURATION_MS 为主命令的耗时(毫秒)
+	On string `json:",omitempty"`
+}
+
+const (
+	onSuccess = "success"
+	onFailure = "failure"
+	onAlways  = "always"
+)
+
+// checkOn 检查 On 的值是否是支持的，stage 为命令所在的 Pre 或者 Post，On 仅对 Post 有效
+func (c *Command) checkOn(stage string) error {
+	switch c.On {
+	case "":
+		return nil
+	case onSuccess, onFailure, onAlways:
+		if stage != stagePost {
+			return errors.New("only works in Post")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid value %q, should be one of %q", c.On, []string{onSuccess, onFailure, onAlways})
+	}
+}
+
+// checkOn 检查 Pre、Post 中命令的 On
+func (r *Rule) checkOn() error {
+	for idx, pc := range r.Pre {
+		if err := pc.checkOn(stagePre); err != nil {
+			return fmt.Errorf("Pre[%d].On: %w", idx, err)
+		}
+	}
+	for idx, pc := range r.Post {
+		if err := pc.checkOn(stagePost); err != nil {
+			return fmt.Errorf("Post[%d].On: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+// matchOn 根据主命令的执行结果 mr 判断是否需要执行，mr 为 nil 时(Pre)总是执行
+func (c *Command) matchOn(mr *ExecResult) bool {
+	if mr == nil {
+		return true
+	}
+	switch c.On {
+	case onAlways:
+		return true
+	case onFailure:
+		return mr.Err != nil
+	default:
+		return mr.Err == nil
+	}
+}
+
+// defaultGroup Parallel = true 且没有配置 Group 时使用的分组
+const defaultGroup = "parallel"
+
+// group 命令的并行分组，为空表示顺序执行
+func (c *Command) group() string {
+	if c.Group != "" {
+		return c.Group
+	}
+	if c.Parallel {
+		return defaultGroup
+	}
+	return ""
 }
 
 func (c *Command) IsMatch(str string) (bool, error) {
@@ -64,26 +155,7 @@ func (c *Command) CanRun() bool {
 	if c.Skip {
 		return false
 	}
-	if len(c.Cond) == 0 {
-		return true
-	}
-	for idx, item := range c.Cond {
-		start := time.Now()
-		ok := item.Allow()
-		if c.Trace {
-			var okStr string
-			if ok {
-				okStr = xcolor.GreenString("true")
-			} else {
-				okStr = xcolor.HiBlackString("false")
-			}
-			log.Printf("Check Condition %2d: %s = %s, cost = %s", idx, xcolor.CyanString(string(item)), okStr, common.CostString(time.Since(start)))
-		}
-		if !ok {
-			return false
-		}
-	}
-	return true
+	return allowConditions(c.Cond, c.Trace)
 }
 
 func (c *Command) getTimeout() time.Duration {
@@ -93,18 +165,30 @@ func (c *Command) getTimeout() time.Duration {
 	return time.Minute
 }
 
-// Exec 执行命令
+// Exec 执行命令，返回执行结果
 // env 变量已经包含了 os.Environ()
-func (c *Command) Exec(ctx context.Context, env []string) {
-	actuator.Trace.Store(c.Trace)
+func (c *Command) Exec(ctx context.Context, env []string) *ExecResult {
+	return c.run(ctx, env, nil, nil)
+}
+
+// run 执行命令，返回执行结果
+// stdout、stderr 为 nil 时使用 os.Stdout、os.Stderr，日志也输出到 stderr 中
+func (c *Command) run(ctx context.Context, env []string, stdout io.Writer, stderr io.Writer) *ExecResult {
+	logger := log.Default()
+	if stderr != nil {
+		logger = log.New(stderr, log.Prefix(), log.Flags())
+	}
 
 	// 将当前命令所特有的环境变量放在最后：覆盖之前的值
 	env = dedupEnv(caseInsensitiveEnv, append(env, c.Env...))
 
 	co := &actuator.Config{
-		Name: c.Cmd,
-		Args: slices.Clone(c.Args),
-		Env:  env,
+		Name:   c.Cmd,
+		Args:   slices.Clone(c.Args),
+		Env:    env,
+		Stdout: stdout,
+		Stderr: stderr,
+		Trace:  c.Trace,
 	}
 	var logMsg string
 	if c.Trace {
@@ -116,7 +200,7 @@ func (c *Command) Exec(ctx context.Context, env []string) {
 		if len(timeout) != 0 {
 			logMsg += ", Timeout=" + timeout
 		}
-		log.Println("[Begin]", logMsg)
+		logger.Println("[Begin]", logMsg)
 	}
 	start := time.Now()
 	err := co.Run(ctx)
@@ -128,21 +212,33 @@ func (c *Command) Exec(ctx context.Context, env []string) {
 		} else {
 			logMsg += "nil"
 		}
-		log.Println("[ End ]", logMsg)
+		logger.Println("[ End ]", logMsg)
+	}
+	result := &ExecResult{
+		Name:     c.Cmd,
+		Status:   statusOK,
+		ExitCode: co.ExitCode(),
+		Cost:     cost,
+		Err:      err,
 	}
 	if err == nil {
-		return
+		return result
+	}
+	result.Status = statusFailed
+	if c.AllowFail {
+		result.Status = statusAllowFail
+	}
+	// 如命令不存在时，没有退出码
+	if result.ExitCode == 0 {
+		result.ExitCode = 1
 	}
 	msg := fmt.Sprintf("Exec %s failed: %s, ExitCode=%d", c.Cmd, err.Error(), co.ExitCode())
 	if c.AllowFail {
 		msg += ", skipped"
 	}
 	if c.Trace {
-		log.Printf("Cmd=%q, args=%q", c.Cmd, c.Args)
-		log.Println(xcolor.RedString(msg))
-	}
-	if !c.AllowFail {
-		exitCode := co.ExitCode()
-		os.Exit(exitCode)
+		logger.Printf("Cmd=%q, args=%q", c.Cmd, c.Args)
+		logger.Println(xcolor.RedString(msg))
 	}
+	return result
 }
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"log"
 	"os"
@@ -18,6 +19,8 @@ import (
 	"time"
 
 	"github.com/xanygo/anygo/cli/xcolor"
+
+	"github.com/fsgo/bin-auto-switcher/internal/common"
 )
 
 type Condition string
@@ -40,6 +43,46 @@ func (c Condition) Allow() bool {
 	return false
 }
 
+// Check 检查条件是否是支持的
+func (c Condition) Check() error {
+	str := strings.TrimSpace(string(c))
+	if len(str) == 0 {
+		return nil
+	}
+	if _, ok := conditions[str]; ok {
+		return nil
+	}
+	name, value, _ := strings.Cut(str, " ")
+	if _, ok := conditionsFuncs[name]; !ok {
+		return fmt.Errorf("unknown condition %q", name)
+	}
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("condition %q requires a value", name)
+	}
+	return nil
+}
+
+// allowConditions 所有条件都满足时返回 true，conds 为空时也返回 true
+func allowConditions(conds []Condition, trace bool) bool {
+	for idx, item := range conds {
+		start := time.Now()
+		ok := item.Allow()
+		if trace {
+			var okStr string
+			if ok {
+				okStr = xcolor.GreenString("true")
+			} else {
+				okStr = xcolor.HiBlackString("false")
+			}
+			log.Printf("Check Condition %2d: %s = %s, cost = %s", idx, xcolor.CyanString(string(item)), okStr, common.CostString(time.Since(start)))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
 var conditions = map[string]func() bool{
 	"go_module": inGoModule,
 }
@@ -63,6 +106,7 @@ func inGoModule() bool {
 
 // gitStatusChange 判断状态为修改和新增的
 func gitStatusChange(str string) bool {
+	disableRuleCache("condition git_status_change")
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 	gitBin := getRawBinName("git")
@@ -102,6 +146,7 @@ func hasFile(name string) bool {
 	}
 	for i := 0; i < strings.Count(wd, string(filepath.Separator)); i++ {
 		fp := filepath.Join(wd, name)
+		watchPath(fp)
 		st, err := os.Stat(fp)
 		if err == nil && !st.IsDir() {
 			return true
@@ -116,6 +161,7 @@ func hasFile(name string) bool {
 }
 
 func condExec(v string) bool {
+	disableRuleCache("condition exec " + v)
 	v = strings.TrimSpace(v)
 	if len(v) == 0 {
 		return false
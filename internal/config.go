@@ -32,8 +32,8 @@ type Config struct {
 	// binName 当前命令，如 go、git 等
 	binName string
 
-	// fileName 当前配置对应的配置文件地址
-	fileName string
+	// fileNames 当前配置对应的所有配置文件地址，按照合并顺序：全局配置在前，越靠近当前目录的越靠后
+	fileNames []string
 
 	Rules []*Rule
 
@@ -44,9 +44,30 @@ type Config struct {
 	// 跳过所有的命令
 	Skip bool
 
+	// Strict 严格模式，没有规则匹配当前目录，或者找不到 Spec 要求的版本时，返回错误，
+	// 而不是使用第一条规则或者默认的命令，如在 CI 中使用
+	Strict bool `json:",omitempty"`
+
 	// Spec 不同命令，特殊的配置
 	// 每种命令的配置都不同,详见 spec.go
 	Spec map[string]any
+
+	// Include 引入其他的配置文件，可选，被引入的配置先加载，当前文件的配置合并到其之上
+	// 相对路径相对于当前配置文件所在目录，支持 "~/" 开头
+	// 如 ["~/.config/bas/shared/go-lint.toml", "../common/git-hooks.toml"]
+	Include []string `json:",omitempty"`
+
+	// bools 配置文件中明确配置了的 Trace 等 bool 字段，用于合并
+	bools boolFields
+
+	// toolPick .tool-versions 选择的版本，各 Spec 据此跳过自身的版本文件
+	toolPick *toolPick
+
+	// lockFile 解析规则时找到的 .bas/lock.toml，为空时不检查
+	lockFile string
+
+	// dryRun 只解析 Spec 并记录决策，不安装缺失的版本，用于 bas info
+	dryRun bool
 }
 
 // Format 格式化配置内容
@@ -59,13 +80,25 @@ func (c *Config) Format() error {
 				rawBinName = getRawBinName(c.binName)
 			}
 			if len(rawBinName) == 0 {
-				return fmt.Errorf("%s rule[%d].Cmd is empty, cannot found %q", c.fileName, idx, c.binName)
+				return fmt.Errorf("%q rule[%d].Cmd is empty, cannot found %q", c.fileNames, idx, c.binName)
 			}
 			r.Cmd = rawBinName
 		}
 		if e := r.Format(); e != nil {
 			return e
 		}
+		if e := checkNeeds(r.Pre, false); e != nil {
+			return fmt.Errorf("%q rule[%d].Pre: %w", c.fileNames, idx, e)
+		}
+		if e := checkNeeds(r.Post, false); e != nil {
+			return fmt.Errorf("%q rule[%d].Post: %w", c.fileNames, idx, e)
+		}
+		if e := r.checkArgs(); e != nil {
+			return fmt.Errorf("%q rule[%d].%w", c.fileNames, idx, e)
+		}
+		if e := r.checkOn(); e != nil {
+			return fmt.Errorf("%q rule[%d].%w", c.fileNames, idx, e)
+		}
 	}
 	return nil
 }
@@ -90,7 +123,18 @@ func (c *Config) Rule() (*Rule, error) {
 	var ms []tmpRule
 	for idx, rule := range c.Rules {
 		score := rule.Match(wd)
+		if score > 0 && len(rule.When) > 0 {
+			if c.Trace {
+				log.Printf("Check Rule %d When: %q\n", idx, rule.When)
+			}
+			if allowConditions(rule.When, c.Trace) {
+				score += len(rule.When) * whenScore
+			} else {
+				score = 0
+			}
+		}
 		if score > 0 {
+			rule.matched = rule.matchedBy(wd, score)
 			item := tmpRule{
 				Rule:  rule,
 				Score: score,
@@ -102,6 +146,9 @@ func (c *Config) Rule() (*Rule, error) {
 			if c.Skip {
 				item.Rule.Skip = true
 			}
+			if c.Strict {
+				item.Rule.Strict = true
+			}
 			ms = append(ms, item)
 		}
 	}
@@ -109,10 +156,15 @@ func (c *Config) Rule() (*Rule, error) {
 	var using int
 	if c.Trace {
 		defer func() {
-			log.Printf("TotalRecords %d rules, using Rule %d\n", len(ms), using)
+			log.Printf("TotalRecords %d rules, using Rule %d, %s\n", len(ms), using, c.Rules[using].matched)
 		}()
 	}
-	if len(ms) < 2 {
+	if len(ms) == 0 {
+		if c.Strict {
+			return nil, fmt.Errorf("no rule matched %q in %q, checked %d rules by Dir and When, "+
+				"hint: add a rule for this dir, or a rule without Dir and When as the default", wd, c.fileNames, len(c.Rules))
+		}
+		c.Rules[0].matched = "default, no rules matched"
 		return c.Rules[0], nil
 	}
 
@@ -126,42 +178,105 @@ func (c *Config) Rule() (*Rule, error) {
 type Rule struct {
 	Cmd string
 
-	Skip bool     `json:",omitempty"` // 是否跳过此规则
-	Dir  []string `json:",omitempty"`
+	Skip bool `json:",omitempty"` // 是否跳过此规则
+
+	// Dir 当前规则生效的目录，可选，支持普通目录、glob 和正则，详见 dirPattern
+	// 如 ["~/workspace/myserver", "~/workspace/*-legacy", "~/src/**/service", "regexp:^/home/work/.+/api/"]
+	Dir []string `json:",omitempty"`
+
+	// When 当前规则生效的额外条件，可选，需要全部满足，和 Command.Cond 相同
+	// 如 ["has_file .nvmrc"]、["go_module", "in_dir service"]
+	// 和 Dir 同时配置时，需要都满足
+	When []Condition `json:",omitempty"`
+
+	// Args 主命令额外的参数，放在参数的最前面，如 git 的 ["-c", "color.ui=always"]
 	Args []string `json:",omitempty"`
-	Env  []string `json:",omitempty"`
+
+	// ArgsAppend 主命令额外的参数，放在参数的最后面
+	ArgsAppend []string `json:",omitempty"`
+
+	// Alias 子命令的别名，参数的第一个为别名时，替换为别名的值，如 git 的 st = "status -sb"
+	Alias map[string]string `json:",omitempty"`
+
+	// Rewrite 使用正则改写参数，在 Alias 之后依次执行，如 Match = "^push$", Replace = "push --force-with-lease"
+	Rewrite []*ArgsRewrite `json:",omitempty"`
+
+	Env []string `json:",omitempty"`
+
+	// Version 当前规则使用的命令版本，可选，优先于项目中的版本文件(如 go.mod)
+	// 有 Spec 的命令使用 Spec 的查找逻辑，如 go 的 "1.20" 使用已安装的最新的 go1.20.x，
+	// 其他命令在 PATH 中查找 {name}{version}，如 terraform1.5.7
+	Version string `json:",omitempty"`
 
 	Pre  []*Command `json:",omitempty"`
 	Post []*Command `json:",omitempty"`
 
+	// Concurrency Pre、Post 中同一 Group 的命令并行执行时的最大并发数，可选，默认为 CPU 核数
+	Concurrency int `json:",omitempty"`
+
 	Trace bool `json:",omitempty"`
 
+	// Strict 严格模式，找不到 Spec 要求的版本时返回错误，而不是使用默认的命令
+	Strict bool `json:",omitempty"`
+
 	Spec map[string]any `json:",omitempty"`
+
+	dirs []*dirPattern
+
+	// matched 当前规则被选中的原因
+	matched string
+
+	// decisions Spec 对 Cmd、Env 等所做的修改及其原因，用于 trace 日志和 bas info
+	decisions []string
+
+	// bools 配置文件中明确配置了的 Trace 等 bool 字段，用于合并
+	bools boolFields
+
+	// toolPick .tool-versions 选择的版本，各 Spec 据此跳过自身的版本文件
+	toolPick *toolPick
+
+	// lockFile 解析规则时找到的 .bas/lock.toml，为空时不检查
+	lockFile string
+
+	// dryRun 只解析 Spec 并记录决策，不安装缺失的版本，用于 bas info
+	dryRun bool
 }
 
+// whenScore 规则的 When 中每个条件满足时增加的分值
+const whenScore = 10
+
+// Match 判断规则是否匹配当前目录 wd，返回匹配的分值，0 为不匹配
+// 当有多个 Dir 都匹配时，使用分值最高的
 func (r *Rule) Match(wd string) int {
+	score, _ := r.matchDir(wd)
+	return score
+}
+
+func (r *Rule) matchDir(wd string) (score int, dir string) {
 	if len(r.Dir) == 0 {
-		return 1
+		return 1, ""
 	}
-
-	for _, dir := range r.Dir {
-		if len(dir) == 0 {
-			return 1
+	for _, dp := range r.dirs {
+		if len(dp.raw) == 0 {
+			return 1, ""
 		}
-
-		if strings.HasPrefix(wd, dir) {
-			return len(dir) * 5
+		if s := dp.Match(wd); s > score {
+			score, dir = s, dp.raw
 		}
 	}
-	return 0
+	return score, dir
 }
 
-// Merge 将 b merge 到 r
-func (r *Rule) Merge(b *Rule) {
-	// 目前只需要 merge Cmd 这一个值
-	if b.Cmd != "" {
-		r.Cmd = b.Cmd
+func (r *Rule) matchedBy(wd string, score int) string {
+	_, dir := r.matchDir(wd)
+	msg := fmt.Sprintf("Score=%d", score)
+	if dir != "" {
+		msg += fmt.Sprintf(", Dir=%q", dir)
 	}
+	if len(r.When) > 0 {
+		msg += fmt.Sprintf(", When=%q", r.When)
+	}
+	return msg
 }
 
 // Format 格式化当前配置
@@ -169,17 +284,15 @@ func (r *Rule) Format() error {
 	if len(r.Dir) == 0 {
 		return nil
 	}
+	r.dirs = make([]*dirPattern, 0, len(r.Dir))
 	for i := 0; i < len(r.Dir); i++ {
-		dir := r.Dir[i]
-		if len(dir) == 0 {
-			continue
-		}
-		dir = filepath.Clean(dir)
-		if strings.HasPrefix(dir, "~") {
-			dir = filepath.Join(homeDir, dir[1:])
-		}
-		dir = dir + string(filepath.Separator)
+		dir := formatDir(r.Dir[i])
 		r.Dir[i] = dir
+		dp, err := newDirPattern(dir)
+		if err != nil {
+			return err
+		}
+		r.dirs = append(r.dirs, dp)
 	}
 	return nil
 }
@@ -188,10 +301,23 @@ const caseInsensitiveEnv = runtime.GOOS == "windows"
 
 // var signalsToIgnore = []os.Signal{os.Interrupt, syscall.SIGQUIT}
 
-func (r *Rule) Run(ctx context.Context, args []string) {
+// Run 依次执行 Pre、主命令和 Post，返回程序的退出码：
+// 第一个失败(且不允许失败)的 Pre 命令的退出码，主命令失败时为主命令的退出码，
+// 否则为第一个失败(且不允许失败)的 Post 命令的退出码，或者 0
+// 主命令失败后，仍然执行 On 为 failure、always 的 Post 命令，
+// Pre 失败时不执行主命令，以失败的 Pre 命令的结果作为主命令的结果，执行 On 为 failure、always 的 Post 命令
+// args 先经过 Alias 和 Rewrite 改写，Pre、Post 的 Match 使用改写后的参数，主命令再加上 Args 和 ArgsAppend
+func (r *Rule) Run(ctx context.Context, args []string) (exitCode int) {
+	setLogPrefix("Before")
+
 	cmdName := r.Cmd
-	cmdArgs := slices.Clone(args)
-	cmdArgsStr := strings.Join(cmdArgs, " ")
+	args, err := r.rewriteArgs(slices.Clone(args))
+	if err != nil {
+		log.Println(xcolor.RedString("rewrite args failed: %v", err))
+		return 1
+	}
+	cmdArgs := r.cmdArgs(args)
+	cmdArgsStr := strings.Join(args, " ")
 
 	env := dedupEnv(caseInsensitiveEnv, append(os.Environ(), r.Env...))
 	env = append(env, fmt.Sprintf(envKey("CMD")+"=%s", cmdName))
@@ -199,15 +325,28 @@ func (r *Rule) Run(ctx context.Context, args []string) {
 
 	// signal.Notify(make(chan os.Signal), signalsToIgnore...)
 
-	setLogPrefix("Before")
-
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	noHooks := disableHooks()
 
+	var results []*ExecResult
+	defer func() {
+		if needSummary(results, r.Trace) {
+			setLogPrefix("Summary")
+			log.Printf("ExitCode=%d\n", exitCode)
+			printSummary(os.Stderr, results)
+		}
+	}()
+
 	if !noHooks {
-		r.execCmds(ctx, r.Pre, cmdArgsStr, env)
+		pre := r.execCmds(ctx, stagePre, r.Pre, cmdArgsStr, env, nil)
+		results = append(results, pre...)
+		if f := firstFatal(pre); f != nil {
+			setLogPrefix("After")
+			results = append(results, r.execPost(ctx, f, cmdArgsStr, env)...)
+			return f.ExitCode
+		}
 	}
 
 	setLogPrefix("Main")
@@ -217,13 +356,28 @@ func (r *Rule) Run(ctx context.Context, args []string) {
 		Args:  cmdArgs,
 		Trace: r.Trace,
 	}
-	mc.Exec(ctx, env)
+	mr := mc.Exec(ctx, env)
+	mr.Stage = stageMain
+	results = append(results, mr)
 
 	setLogPrefix("After")
 	if !noHooks {
-		r.execCmds(ctx, r.Post, cmdArgsStr, env)
+		post := r.execPost(ctx, mr, cmdArgsStr, env)
+		results = append(results, post...)
+		if f := firstFatal(post); f != nil && mr.Err == nil {
+			return f.ExitCode
+		}
 	}
-	os.Exit(0)
+	return mr.ExitCode
+}
+
+// execPost 执行 Post 中的命令，mr 为主命令的执行结果，或者 Pre 失败时失败的 Pre 命令的结果
+func (r *Rule) execPost(ctx context.Context, mr *ExecResult, argsStr string, env []string) []*ExecResult {
+	env = append(env,
+		fmt.Sprintf("%s=%d", envKey("EXIT_CODE"), mr.ExitCode),
+		fmt.Sprintf("%s=%d", envKey("DURATION_MS"), mr.Cost.Milliseconds()),
+	)
+	return r.execCmds(ctx, stagePost, r.Post, argsStr, env, mr)
 }
 
 func (r *Rule) BeforeExec(ctx context.Context, name string) error {
@@ -233,7 +387,7 @@ func (r *Rule) BeforeExec(ctx context.Context, name string) error {
 	if disableHooks() {
 		return nil
 	}
-	if len(r.Spec) > 0 {
+	if len(r.Spec) > 0 || r.Version != "" {
 		if err := parserSpecial(name, r); err != nil {
 			return err
 		}
@@ -241,53 +395,121 @@ func (r *Rule) BeforeExec(ctx context.Context, name string) error {
 	return ctx.Err()
 }
 
-func (r *Rule) execCmds(ctx context.Context, cmds []*Command, argsStr string, env []string) {
-	if len(cmds) == 0 {
-		return
+// decide 记录 Spec 所做的决策
+func (r *Rule) decide(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	r.decisions = append(r.decisions, msg)
+	if r.Trace {
+		log.Println(msg)
 	}
+}
 
-	for idx, pc := range cmds {
-		if len(pc.Cmd) == 0 {
-			continue
+// execCmds 执行 Pre 或者 Post 中的命令，返回执行的结果，遇到失败(且不允许失败)的命令时停止执行
+// stage: Pre 或者 Post
+// mr: 主命令的执行结果，用于判断 Post 中命令的 On，执行 Pre 时为 nil
+func (r *Rule) execCmds(ctx context.Context, stage string, cmds []*Command, argsStr string, env []string, mr *ExecResult) []*ExecResult {
+	if len(cmds) == 0 {
+		return nil
+	}
+	var results []*ExecResult
+	defer func() {
+		for _, er := range results {
+			er.Stage = stage
 		}
+	}()
 
-		m, err := pc.IsMatch(argsStr)
-		if err != nil {
-			log.Println(xcolor.RedString(err.Error()))
-			os.Exit(1)
-		}
-		if !m {
-			continue
-		}
-		// 只能在 action 匹配后，才允许打印日志
+	if hasNeeds(cmds) {
+		results = r.execDAG(ctx, cmds, argsStr, env, mr)
+		return results
+	}
 
-		if pc.Trace {
-			log.Printf("%s[%s] > %s\n", xcolor.CyanString("Cmd"), xcolor.CyanString("%02d", idx), xcolor.GreenString(pc.Cmd))
+	groups := map[string]bool{}
+	for idx, pc := range cmds {
+		if g := pc.group(); g != "" {
+			// 同一组的命令，在组中第一个命令的位置一起执行
+			if groups[g] {
+				continue
+			}
+			groups[g] = true
+			if err := ctx.Err(); err != nil {
+				log.Println("context canceled:", err.Error())
+				break
+			}
+			grs := r.execGroup(ctx, g, cmds, idx, argsStr, env, mr)
+			results = append(results, grs...)
+			if firstFatal(grs) != nil {
+				break
+			}
+			continue
 		}
 
-		if !pc.CanRun() {
-			if pc.Trace {
-				log.Println(xcolor.HiBlackString("No conditions matched. Skipped."))
+		run, er := r.prepare(idx, pc, argsStr, mr)
+		if er != nil {
+			results = append(results, er)
+			if er.Fatal() {
+				break
 			}
+		}
+		if !run {
 			continue
 		}
 
-		if err = ctx.Err(); err != nil {
+		if err := ctx.Err(); err != nil {
 			log.Println("context canceled:", err.Error())
 			break
 		}
 
-		if r.Trace {
-			pc.Trace = r.Trace
-		}
-
-		func() {
+		er = func() *ExecResult {
 			timeout := pc.getTimeout()
 			ctx1, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
-			pc.Exec(ctx1, env)
+			return pc.Exec(ctx1, env)
 		}()
+		er.Name = pc.name(idx)
+		results = append(results, er)
+		if er.Fatal() {
+			break
+		}
+	}
+	return results
+}
+
+// prepare 判断第 idx 个命令 pc 是否需要执行，
+// 不执行时，若不是因为 Match 不匹配，同时返回其结果：Cond 不满足时为跳过，Match 错误时为失败
+func (r *Rule) prepare(idx int, pc *Command, argsStr string, mr *ExecResult) (bool, *ExecResult) {
+	if len(pc.Cmd) == 0 {
+		return false, nil
+	}
+
+	m, err := pc.IsMatch(argsStr)
+	if err != nil {
+		log.Println(xcolor.RedString(err.Error()))
+		return false, &ExecResult{Name: pc.name(idx), Status: statusFailed, ExitCode: 1, Err: err}
 	}
+	if !m || !pc.matchOn(mr) {
+		return false, nil
+	}
+	// 只能在 action 匹配后，才允许打印日志
+
+	if pc.Trace {
+		log.Printf("%s[%s] > %s\n", xcolor.CyanString("Cmd"), xcolor.CyanString("%02d", idx), xcolor.GreenString(pc.Cmd))
+	}
+
+	if !pc.CanRun() {
+		if pc.Trace {
+			log.Println(xcolor.HiBlackString("No conditions matched. Skipped."))
+		}
+		note := "Cond not matched"
+		if pc.Skip {
+			note = "Skip = true"
+		}
+		return false, newSkipped(pc.name(idx), note)
+	}
+
+	if r.Trace {
+		pc.Trace = r.Trace
+	}
+	return true, nil
 }
 
 func configDir() string {
@@ -298,13 +520,15 @@ func globalConfigPath(name string) string {
 	return filepath.Join(configDir(), name+".toml")
 }
 
-func localConfigPath(name string) (string, error) {
+// localConfigPaths 查找当前目录以及所有上级目录的 .bas/{name}.toml，越靠近根目录的越靠前
+func localConfigPaths(name string) ([]string, error) {
 	fp := filepath.Join(".bas", name+".toml")
-	np, err := findFileUpper(fp, 128)
-	if err != nil && errors.Is(err, errFileNotFound) {
-		return "", nil
+	fps, err := findFilesUpper(fp, 128)
+	if err != nil {
+		return nil, err
 	}
-	return np, err
+	slices.Reverse(fps)
+	return fps, nil
 }
 
 func fileExists(p string) (bool, error) {
@@ -321,27 +545,30 @@ func fileExists(p string) (bool, error) {
 	return false, err
 }
 
+// LoadConfig 加载并合并 name 命令的所有配置文件
+//
+// 合并顺序为：全局配置 ~/.config/bas/{name}.toml，
+// 然后从上级目录到当前目录的每一个 .bas/{name}.toml，后加载的覆盖先加载的，详见 Config.Merge
 func LoadConfig(name string) (*Config, error) {
-	fileName, err := localConfigPath(name)
+	fileNames, err := localConfigPaths(name)
 	if err != nil {
 		return nil, err
 	}
 	if enableTrace {
-		log.Printf("Local ConfigPath = %q %v\n", fileName, err)
+		log.Printf("Local ConfigPaths = %q\n", fileNames)
 	}
 
-	if len(fileName) == 0 {
-		fp := globalConfigPath(name)
-		ok, err := fileExists(fp)
-		if enableTrace {
-			log.Printf("Global ConfigPath = %q, exists=%v err=%v\n", fp, ok, err)
-		}
-		if ok {
-			fileName = fp
-		}
-		if err != nil {
-			return nil, err
-		}
+	fp := globalConfigPath(name)
+	watchPath(fp)
+	ok, err := fileExists(fp)
+	if enableTrace {
+		log.Printf("Global ConfigPath = %q, exists=%v err=%v\n", fp, ok, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		fileNames = append([]string{fp}, fileNames...)
 	}
 
 	cfg := &Config{
@@ -349,15 +576,18 @@ func LoadConfig(name string) (*Config, error) {
 		Trace:   enableTrace,
 	}
 
-	cfg.fileName = fileName
-	if len(fileName) != 0 {
-		if err = xcfg.Parse(fileName, &cfg); err != nil {
+	loaded := map[string]bool{}
+	for _, fileName := range fileNames {
+		layer, err := parseConfigFile(fileName, nil, loaded)
+		if err != nil {
 			return nil, err
 		}
+		cfg.Merge(layer)
+		cfg.fileNames = append(cfg.fileNames, layer.fileNames...)
 	}
 
 	if cfg.Trace {
-		log.Printf("Using ConfigPath = %q\n", cfg.fileName)
+		log.Printf("Using ConfigPaths = %q\n", cfg.fileNames)
 	}
 
 	if len(cfg.Rules) == 0 {
@@ -377,21 +607,103 @@ func LoadConfig(name string) (*Config, error) {
 	return cfg, nil
 }
 
+// includePath 返回配置文件 fp 中 Include 的文件 name 的路径，相对路径相对于 fp 所在的目录
+func includePath(fp string, name string) string {
+	ip := expandHome(name)
+	if !filepath.IsAbs(ip) {
+		ip = filepath.Join(filepath.Dir(fp), ip)
+	}
+	return ip
+}
+
+// parseConfigFile 解析一个配置文件，并递归的加载其 Include 的配置文件
+// stack: 当前正在加载的配置文件链，用于检查循环引用
+// loaded: 本次加载过程中已经加载过的配置文件，同一个文件只加载一次，
+// 如 A 引入了 B 和 C，B 和 C 都引入了 D，D 只在 B 中加载，避免其 Pre、Post 重复执行
+func parseConfigFile(fileName string, stack []string, loaded map[string]bool) (*Config, error) {
+	fp, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(stack, fp) {
+		return nil, fmt.Errorf("include cycle detected: %s", strings.Join(append(stack, fp), " -> "))
+	}
+	if loaded[fp] {
+		if enableTrace {
+			log.Printf("%q already loaded, skipped\n", fp)
+		}
+		return &Config{}, nil
+	}
+	loaded[fp] = true
+	stack = append(stack, fp)
+	watchPath(fp)
+	watchConfigEnv(fp)
+
+	cur := &Config{}
+	if err = xcfg.Parse(fp, &cur); err != nil {
+		return nil, err
+	}
+	if err = parseBools(fp, cur); err != nil {
+		return nil, err
+	}
+	if len(cur.Include) == 0 {
+		cur.fileNames = []string{fp}
+		return cur, nil
+	}
+
+	result := &Config{}
+	for _, name := range cur.Include {
+		ip := includePath(fp, name)
+		if enableTrace {
+			log.Printf("%q Include %q\n", fp, ip)
+		}
+		inc, err := parseConfigFile(ip, stack, loaded)
+		if err != nil {
+			return nil, fmt.Errorf("%s include %q: %w", fp, name, err)
+		}
+		result.Merge(inc)
+		result.fileNames = append(result.fileNames, inc.fileNames...)
+	}
+	result.Merge(cur)
+	result.fileNames = append(result.fileNames, fp)
+	return result, nil
+}
+
 var configTpl = `
+# Optional, include other config files, relative path is relative to this file
+# Include = ["~/.config/bas/shared/git-hooks.toml"]
+
 # Optional, enable print trace log
 # or with env "BAS_Trace=true" to enable it
 # Trace = false
 
+# Optional, fail when no rule matched the current dir,
+# or the version required by Spec not found, e.g. in CI
+# Strict = false
+
 [[Rules]]
 Cmd = "{CMD}"                  # Optional
-# Args = [""]                  # Optional, extra args for command
+# Args = [""]                  # Optional, extra args for command, put before the args
+# ArgsAppend = [""]            # Optional, extra args for command, put after the args
 # Env = ["k1=v1","k2=v2"]      # Optional, extra env variable for command
+# Version = ""                 # Optional, e.g. "1.20" uses the newest installed go1.20.x
 # Trace = false                # Optional, print trace log
+# Strict = false               # Optional, fail when the version required by Spec not found
+# Concurrency = 4              # Optional, max concurrency of the hooks in a Group, default is the number of CPUs
+
+# [Rules.Alias]                # Optional, alias of the first arg, e.g. "git st" -> "git status -sb"
+# st = "status -sb"
+
+# [[Rules.Rewrite]]            # Optional, rewrite the args by regexp, after Alias
+# Match = "^push$"
+# Replace = "push --force-with-lease"
 
 # -----------------------------------------------------------------------------
 # with env "BAS_NoHook=true" to disable Pre and Post Hooks
 #
 # [[Rules.Pre]]                # Optional, prepare hook command
+# ID = ""                     # Optional, hook with the same ID in nearer config file replaces it
+# Needs = [""]                 # Optional, IDs of the hooks it depends on, hooks run as a graph when any has Needs
 # Match = ""                   # Optional, regexp for args, eg "^add\\s" for "git add ."
 # Trace = false                # Optional, print trace log
 
@@ -403,21 +715,27 @@ Cmd = "{CMD}"                  # Optional
 # Args  = [""]                 # Optional
 # AllowFail = true/false       # Optional, break when exec failed
 # Timeout = "2m"               # Optional, exec timeout, default 1 min
+# Group = ""                   # Optional, hooks in the same group run concurrently, output is printed in order
+# Parallel = false             # Optional, same as Group = "parallel"
 #
 # -----------------------------------------------------------------------------
 # [[Rules.Post]]               # Optional, Post hook command，same as Rules.Pre
 # Cmd  = ""
 # Args = [""]
+# On   = "success"             # Optional, run on the main command's "success"(default), "failure" or "always"
+#                              # env BAS_EXIT_CODE and BAS_DURATION_MS are the main command's exit code and cost
 # -----------------------------------------------------------------------------
 
 # =============================================================================
 # Rule for some dirs
 # =============================================================================
 # [[Rules]]
-# Dir = ["/home/work/dir_1/"]   # Required
+# Dir = ["/home/work/dir_1/"]   # Required, also support glob "~/src/*/service" and "regexp:^/home/.+/api/"
+# When = ["has_file .nvmrc"]    # Optional, conditions for this rule, same as Rules.Pre.Cond
 # Cmd = "{CMD}"                 # Optional
-# Args = [""]                   # Optional, extra args for command
+# Args = [""]                   # Optional, extra args for command, put before the args
 # Env = ["k1=v1","k2=v2"]       # Optional, extra env variable for command
+# Version = ""                  # Optional, version of the command for this rule
 # ============================================================================
 `
 
@@ -0,0 +1,202 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFile_Include(t *testing.T) {
+	hook := func(cmd string) string {
+		return "[[Rules]]\n[[Rules.Pre]]\nCmd = \"" + cmd + "\"\n"
+	}
+	tests := []struct {
+		name     string
+		files    map[string]string // 相对于临时目录的文件名 -> 内容，从 a.toml 开始加载
+		wantPre  []string
+		wantErr  bool
+		wantFile int
+	}{
+		{
+			name: "relative path",
+			files: map[string]string{
+				"repo/a.toml":   "Include = [\"../shared/b.toml\"]\n" + hook("a"),
+				"shared/b.toml": hook("b"),
+			},
+			wantPre:  []string{"b", "a"},
+			wantFile: 2,
+		},
+		{
+			name: "diamond",
+			files: map[string]string{
+				"repo/a.toml": "Include = [\"b.toml\", \"c.toml\"]\n" + hook("a"),
+				"repo/b.toml": "Include = [\"d.toml\"]\n" + hook("b"),
+				"repo/c.toml": "Include = [\"./d.toml\"]\n" + hook("c"),
+				"repo/d.toml": hook("d"),
+			},
+			wantPre:  []string{"d", "b", "c", "a"},
+			wantFile: 4,
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"repo/a.toml": "Include = [\"b.toml\"]\n" + hook("a"),
+				"repo/b.toml": "Include = [\"a.toml\"]\n" + hook("b"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "self",
+			files: map[string]string{
+				"repo/a.toml": "Include = [\"a.toml\"]\n" + hook("a"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			cfg, err := parseConfigFile(filepath.Join(dir, "repo", "a.toml"), nil, map[string]bool{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(cfg.fileNames) != tt.wantFile {
+				t.Errorf("fileNames = %q, want %d files", cfg.fileNames, tt.wantFile)
+			}
+			if len(cfg.Rules) != 1 {
+				t.Fatalf("len(Rules) = %d, want 1", len(cfg.Rules))
+			}
+			var pre []string
+			for _, c := range cfg.Rules[0].Pre {
+				pre = append(pre, c.Cmd)
+			}
+			if !reflect.DeepEqual(pre, tt.wantPre) {
+				t.Errorf("Pre = %q, want %q", pre, tt.wantPre)
+			}
+		})
+	}
+}
+
+func TestConfig_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    *Rule
+		wantErr string
+	}{
+		{
+			name: "On in Post",
+			rule: &Rule{Cmd: "go", Post: []*Command{{Cmd: "sh", On: onAlways}}},
+		},
+		{
+			name:    "On in Pre",
+			rule:    &Rule{Cmd: "go", Pre: []*Command{{Cmd: "sh"}, {Cmd: "sh", On: onFailure}}},
+			wantErr: "rule[0].Pre[1].On: only works in Post",
+		},
+		{
+			name:    "invalid On",
+			rule:    &Rule{Cmd: "go", Post: []*Command{{Cmd: "sh", On: "fail"}}},
+			wantErr: "rule[0].Post[0].On: invalid value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Rules: []*Rule{tt.rule}}
+			err := cfg.Format()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Format() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Format() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Rule_when(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".nvmrc"), "18\n")
+	t.Chdir(dir)
+
+	hasNvmrc := []Condition{"has_file .nvmrc"}
+	noFile := []Condition{"has_file not_exists.txt"}
+	tests := []struct {
+		name        string
+		rules       []*Rule
+		wantCmd     string
+		wantMatched string // 为 dir 时替换为 Dir 的分值
+	}{
+		{
+			name:        "When beats default",
+			rules:       []*Rule{{Cmd: "a"}, {Cmd: "b", When: hasNvmrc}},
+			wantCmd:     "b",
+			wantMatched: `Score=11, When=["has_file .nvmrc"]`,
+		},
+		{
+			name: "Dir and When beats the same Dir",
+			rules: []*Rule{
+				{Cmd: "a", Dir: []string{dir}},
+				{Cmd: "b", Dir: []string{dir}, When: hasNvmrc},
+				{Cmd: "c", Dir: []string{dir}},
+			},
+			wantCmd:     "b",
+			wantMatched: `Score=dir+10, Dir="dir", When=["has_file .nvmrc"]`,
+		},
+		{
+			name:        "failed When",
+			rules:       []*Rule{{Cmd: "a"}, {Cmd: "b", When: noFile}},
+			wantCmd:     "a",
+			wantMatched: "Score=1",
+		},
+		{
+			name:        "failed When with Dir",
+			rules:       []*Rule{{Cmd: "a"}, {Cmd: "b", Dir: []string{dir}, When: noFile}},
+			wantCmd:     "a",
+			wantMatched: "Score=1",
+		},
+		{
+			name:        "no rule matched",
+			rules:       []*Rule{{Cmd: "a", When: noFile}, {Cmd: "b", When: noFile}},
+			wantCmd:     "a",
+			wantMatched: "default, no rules matched",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Rules: tt.rules}
+			if err := cfg.Format(); err != nil {
+				t.Fatal(err)
+			}
+			r, err := cfg.Rule()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Cmd != tt.wantCmd {
+				t.Errorf("Rule().Cmd = %q, want %q", r.Cmd, tt.wantCmd)
+			}
+			want := tt.wantMatched
+			if strings.Contains(want, "dir") {
+				formatted := tt.rules[0].Dir[0]
+				score := tt.rules[0].Match(formatted)
+				want = strings.Replace(want, "dir+10", strconv.Itoa(score+whenScore), 1)
+				want = strings.Replace(want, `"dir"`, strconv.Quote(formatted), 1)
+			}
+			if r.matched != want {
+				t.Errorf("Rule().matched = %q, want %q", r.matched, want)
+			}
+		})
+	}
+}
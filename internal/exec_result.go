@@ -0,0 +1,100 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fsgo/bin-auto-switcher/internal/common"
+)
+
+type execStatus string
+
+const (
+	statusOK        execStatus = "ok"
+	statusFailed    execStatus = "failed"
+	statusAllowFail execStatus = "failed(allowed)"
+	statusSkipped   execStatus = "skipped"
+)
+
+const (
+	stagePre  = "Pre"
+	stageMain = "Main"
+	stagePost = "Post"
+)
+
+// ExecResult 一个命令(Pre、Post 或者主命令)的执行结果
+type ExecResult struct {
+	Stage    string // Pre、Main、Post
+	Name     string // 命令的 ID，没有时为序号和命令，详见 Command.name
+	Status   execStatus
+	ExitCode int
+	Cost     time.Duration
+	Err      error
+
+	// Note 跳过或者失败的原因
+	Note string
+}
+
+// Fatal 是否是不允许的失败，Pre、Post 中的命令，失败并且未配置 AllowFail
+func (er *ExecResult) Fatal() bool {
+	return er.Status == statusFailed
+}
+
+func (er *ExecResult) isFailed() bool {
+	return er.Status == statusFailed || er.Status == statusAllowFail
+}
+
+// newSkipped 未执行的命令的结果
+func newSkipped(name string, note string) *ExecResult {
+	return &ExecResult{Name: name, Status: statusSkipped, Note: note}
+}
+
+// firstFatal 第一个不允许的失败
+func firstFatal(results []*ExecResult) *ExecResult {
+	idx := slices.IndexFunc(results, (*ExecResult).Fatal)
+	if idx == -1 {
+		return nil
+	}
+	return results[idx]
+}
+
+// needSummary 有 hook 失败时，或者调试模式下，才输出汇总，避免每次执行命令都输出
+func needSummary(results []*ExecResult, trace bool) bool {
+	hooks := slices.ContainsFunc(results, func(er *ExecResult) bool {
+		return er.Stage != stageMain
+	})
+	if !hooks {
+		return false
+	}
+	return trace || slices.ContainsFunc(results, func(er *ExecResult) bool {
+		return er.Stage != stageMain && er.isFailed()
+	})
+}
+
+// printSummary 输出所有命令执行结果的汇总表格
+func printSummary(w io.Writer, results []*ExecResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Stage\tName\tStatus\tExitCode\tCost\tNote")
+	var total time.Duration
+	for _, er := range results {
+		total += er.Cost
+		note := er.Note
+		if er.Err != nil {
+			note = strings.TrimSpace(note + " " + er.Err.Error())
+		}
+		var cost string
+		if er.Status != statusSkipped {
+			cost = common.CostString(er.Cost)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", er.Stage, er.Name, er.Status, er.ExitCode, cost, note)
+	}
+	fmt.Fprintf(tw, "Total\t%d\t\t\t%s\t\n", len(results), common.CostString(total))
+	_ = tw.Flush()
+}
@@ -0,0 +1,117 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRule_Run(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	t.Setenv(envKey("NoHook"), "")
+	sh := func(script string) *Command {
+		return &Command{Cmd: "sh", Args: []string{"-c", script}}
+	}
+	tests := []struct {
+		name    string
+		rule    *Rule
+		args    []string
+		want    int
+		files   []string // Post 中创建的文件
+		noFiles []string // 不应该创建的文件
+	}{
+		{
+			name: "ok",
+			rule: &Rule{Cmd: "sh", Pre: []*Command{sh("exit 0")}, Post: []*Command{sh("exit 0")}},
+			args: []string{"-c", "exit 0"},
+			want: 0,
+		},
+		{
+			name: "main failed",
+			rule: &Rule{Cmd: "sh", Post: []*Command{sh("exit 5")}},
+			args: []string{"-c", "exit 3"},
+			want: 3,
+		},
+		{
+			name: "pre failed",
+			rule: &Rule{Cmd: "sh", Pre: []*Command{sh("exit 4")}},
+			args: []string{"-c", "exit 3"},
+			want: 4,
+		},
+		{
+			name: "pre allow fail",
+			rule: &Rule{Cmd: "sh", Pre: []*Command{{Cmd: "sh", Args: []string{"-c", "exit 4"}, AllowFail: true}}},
+			args: []string{"-c", "exit 0"},
+			want: 0,
+		},
+		{
+			name: "post failed",
+			rule: &Rule{Cmd: "sh", Post: []*Command{sh("exit 5"), sh("exit 6")}},
+			args: []string{"-c", "exit 0"},
+			want: 5,
+		},
+		{
+			name: "post env",
+			rule: &Rule{Cmd: "sh", Post: []*Command{sh(`test "$BAS_EXIT_CODE" = 0 && test -n "$BAS_DURATION_MS" || exit 8`)}},
+			args: []string{"-c", "exit 0"},
+			want: 0,
+		},
+		{
+			name: "post on failure",
+			rule: &Rule{Cmd: "sh", Post: []*Command{
+				{Cmd: "sh", Args: []string{"-c", "exit 5"}, On: onSuccess},
+				{Cmd: "sh", Args: []string{"-c", `touch "$DIR/failure"`}, On: onFailure},
+				{Cmd: "sh", Args: []string{"-c", `test "$BAS_EXIT_CODE" = 3 && touch "$DIR/always"`}, On: onAlways},
+			}},
+			args:  []string{"-c", "exit 3"},
+			want:  3,
+			files: []string{"failure", "always"},
+		},
+		{
+			name: "pre failed runs post on failure",
+			rule: &Rule{
+				Cmd: "sh",
+				Pre: []*Command{sh("exit 4")},
+				Post: []*Command{
+					{Cmd: "sh", Args: []string{"-c", `touch "$DIR/success"`}, On: onSuccess},
+					{Cmd: "sh", Args: []string{"-c", `test "$BAS_EXIT_CODE" = 4 && touch "$DIR/failure"`}, On: onFailure},
+					{Cmd: "sh", Args: []string{"-c", `touch "$DIR/always"; exit 7`}, On: onAlways},
+				},
+			},
+			args:    []string{"-c", `touch "$DIR/main"`},
+			want:    4,
+			files:   []string{"failure", "always"},
+			noFiles: []string{"main", "success"},
+		},
+		{
+			name: "not found",
+			rule: &Rule{Cmd: "bas-not-found-cmd"},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("DIR", dir)
+			if got := tt.rule.Run(context.Background(), tt.args); got != tt.want {
+				t.Fatalf("Run() = %d, want %d", got, tt.want)
+			}
+			for _, name := range tt.files {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, name := range tt.noFiles {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					t.Fatalf("%s should not be created", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/cli/xcolor"
+
+	"github.com/fsgo/bin-auto-switcher/internal/common"
+)
+
+// hasNeeds 是否有命令配置了 Needs，有时所有的命令按照依赖关系执行
+func hasNeeds(cmds []*Command) bool {
+	return slices.ContainsFunc(cmds, func(c *Command) bool {
+		return len(c.Needs) > 0
+	})
+}
+
+// name 命令在日志中的名称，没有 ID 时使用序号和命令
+func (c *Command) name(idx int) string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return fmt.Sprintf("#%d(%s)", idx, c.Cmd)
+}
+
+// checkNeeds 检查命令的 Needs：引用的 ID 是否存在，是否有循环依赖
+// allowUnknown: 是否允许引用不存在的 ID，如单独检查一个配置文件时，ID 可能在其他的配置文件中
+func checkNeeds(cmds []*Command, allowUnknown bool) error {
+	if !hasNeeds(cmds) {
+		return nil
+	}
+	ids := make(map[string]*Command, len(cmds))
+	for idx, c := range cmds {
+		if c.ID == "" {
+			continue
+		}
+		if _, ok := ids[c.ID]; ok {
+			return fmt.Errorf("[%d] duplicate ID %q", idx, c.ID)
+		}
+		ids[c.ID] = c
+	}
+	for idx, c := range cmds {
+		for _, id := range c.Needs {
+			if _, ok := ids[id]; !ok && !allowUnknown {
+				return fmt.Errorf("[%d] %s needs unknown ID %q", idx, c.name(idx), id)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("Needs cycle detected: %s", strings.Join(append(path, id), " -> "))
+		case visited:
+			return nil
+		}
+		c, ok := ids[id]
+		if !ok {
+			return nil
+		}
+		state[id] = visiting
+		for _, need := range c.Needs {
+			if err := visit(need, append(path, id)); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+	for _, c := range cmds {
+		if c.ID != "" {
+			if err := visit(c.ID, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dagNeeds 按照依赖关系执行时，每个命令依赖的命令(在 cmds 中的序号)：
+// 配置了 Needs 的，依赖 Needs 中的命令；
+// 没有配置的，依赖前一个命令，以保持配置的顺序，除非前一个命令(直接或者间接)依赖当前命令，
+// 和前一个命令的 Group 相同时，则和其依赖相同的命令，以并行执行，其后的命令依赖此 Group 中所有的命令
+func dagNeeds(cmds []*Command) [][]int {
+	ids := map[string]int{}
+	for idx, c := range cmds {
+		if _, ok := ids[c.ID]; c.ID != "" && !ok {
+			ids[c.ID] = idx
+		}
+	}
+	needs := make([][]int, len(cmds))
+	for idx, c := range cmds {
+		for _, id := range c.Needs {
+			if i, ok := ids[id]; ok {
+				needs[idx] = append(needs[idx], i)
+			}
+		}
+	}
+
+	// reaches 命令 from 是否(直接或者间接)依赖命令 to
+	var reaches func(from int, to int, seen map[int]bool) bool
+	reaches = func(from int, to int, seen map[int]bool) bool {
+		if from == to {
+			return true
+		}
+		if seen[from] {
+			return false
+		}
+		seen[from] = true
+		return slices.ContainsFunc(needs[from], func(i int) bool {
+			return reaches(i, to, seen)
+		})
+	}
+
+	var prev []int       // 后面的命令默认依赖的：前一个命令，或者前一个 Group 中的所有命令
+	var groupNeeds []int // 当前 Group 中的命令默认依赖的
+	var group string
+	for idx, c := range cmds {
+		if len(c.Needs) > 0 {
+			prev, group = []int{idx}, ""
+			continue
+		}
+		if g := c.group(); g == "" || g != group {
+			groupNeeds, prev, group = prev, nil, g
+		}
+		for _, p := range groupNeeds {
+			if !reaches(p, idx, map[int]bool{}) {
+				needs[idx] = append(needs[idx], p)
+			}
+		}
+		prev = append(prev, idx)
+	}
+	return needs
+}
+
+type dagState int
+
+const (
+	dagPending dagState = iota
+	dagRunning
+	dagDone    // 已执行
+	dagNotRun  // Match 或者 Cond 不满足，未执行
+	dagSkipped // 依赖的命令失败了，跳过
+)
+
+// dagNode 按照依赖关系执行的一个命令
+type dagNode struct {
+	hookTask
+	state dagState
+	needs []*dagNode
+
+	// lvl level 的缓存，为 0 时未计算
+	lvl int
+}
+
+// newDagNodes 为命令创建执行计划中的节点，依赖关系见 dagNeeds
+func newDagNodes(cmds []*Command) []*dagNode {
+	nodes := make([]*dagNode, 0, len(cmds))
+	for idx, c := range cmds {
+		nodes = append(nodes, &dagNode{hookTask: hookTask{cmd: c, idx: idx}})
+	}
+	for idx, needs := range dagNeeds(cmds) {
+		for _, i := range needs {
+			nodes[idx].needs = append(nodes[idx].needs, nodes[i])
+		}
+	}
+	return nodes
+}
+
+// failed 是否执行失败(不允许失败的)，依赖此命令的命令将被跳过
+func (n *dagNode) failed() bool {
+	return n.state == dagSkipped || (n.result != nil && n.result.Fatal())
+}
+
+func (n *dagNode) finished() bool {
+	return n.state >= dagDone
+}
+
+// ready 依赖的命令是否都已结束，以及失败了的依赖
+func (n *dagNode) ready() (bool, *dagNode) {
+	var failed *dagNode
+	for _, need := range n.needs {
+		if !need.finished() {
+			return false, nil
+		}
+		if failed == nil && need.failed() {
+			failed = need
+		}
+	}
+	return true, failed
+}
+
+// level 在执行计划中的阶段，没有依赖的为 1
+// 计算后缓存，避免多个命令依赖同一个命令(菱形的依赖)时重复计算
+func (n *dagNode) level() int {
+	if n.lvl > 0 {
+		return n.lvl
+	}
+	l := 1
+	for _, need := range n.needs {
+		l = max(l, need.level()+1)
+	}
+	n.lvl = l
+	return l
+}
+
+// execDAG 按照依赖关系(见 dagNeeds)执行命令，依赖都执行完成后即开始执行，没有依赖关系的命令并行执行，
+// 最大并发数为 Rule.Concurrency，各命令的输出先缓存，结束时再输出
+// 依赖的命令失败(且不允许失败)时，跳过当前命令，返回按照配置顺序的执行结果
+func (r *Rule) execDAG(ctx context.Context, cmds []*Command, argsStr string, env []string, mr *ExecResult) []*ExecResult {
+	nodes := newDagNodes(cmds)
+
+	limit := r.concurrency()
+	if r.Trace {
+		r.logPlan(nodes, limit)
+	}
+
+	begin := time.Now()
+	finished := make(chan *dagNode)
+	var running int
+	for {
+		// 启动所有依赖已经结束的命令，直到达到并发数限制
+		for changed := true; changed && running < limit; {
+			changed = false
+			for _, n := range nodes {
+				if n.state != dagPending || running >= limit {
+					continue
+				}
+				ok, need := n.ready()
+				if !ok {
+					continue
+				}
+				changed = true
+				if need != nil || ctx.Err() != nil {
+					n.state = dagSkipped
+					note := "context canceled"
+					if need != nil {
+						note = fmt.Sprintf("needs %s failed", need.cmd.name(need.idx))
+					}
+					n.result = newSkipped(n.cmd.name(n.idx), note)
+					log.Println(xcolor.YellowString("Skipped %s: %s", n.cmd.name(n.idx), note))
+					continue
+				}
+				run, er := r.prepare(n.idx, n.cmd, argsStr, mr)
+				if !run {
+					n.state = dagNotRun
+					n.result = er
+					continue
+				}
+				n.state = dagRunning
+				running++
+				n.start(ctx, env, nil, func() { finished <- n })
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+		n := <-finished
+		running--
+		n.state = dagDone
+		n.output.flush()
+	}
+
+	if r.Trace {
+		log.Printf("%s done, Cost=%s\n", xcolor.CyanString("Plan"), common.CostString(time.Since(begin)))
+	}
+	var results []*ExecResult
+	for _, n := range nodes {
+		if n.result != nil {
+			results = append(results, n.result)
+		}
+	}
+	return results
+}
+
+// logPlan 输出执行计划，同一阶段的命令可以并行执行
+func (r *Rule) logPlan(nodes []*dagNode, limit int) {
+	var stages [][]string
+	for _, n := range nodes {
+		if len(n.cmd.Cmd) == 0 {
+			continue
+		}
+		l := n.level()
+		for len(stages) < l {
+			stages = append(stages, nil)
+		}
+		name := n.cmd.name(n.idx)
+		if len(n.cmd.Needs) > 0 {
+			name += fmt.Sprintf(" (needs %s)", strings.Join(n.cmd.Needs, ","))
+		}
+		stages[l-1] = append(stages[l-1], name)
+	}
+	log.Printf("%s: %d stages, Concurrency=%d\n", xcolor.CyanString("Plan"), len(stages), limit)
+	for idx, names := range stages {
+		log.Printf("  Stage %d: %s\n", idx+1, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xanygo/anygo/cli/xcolor"
+)
+
+func TestCheckNeeds(t *testing.T) {
+	tests := []struct {
+		name         string
+		cmds         []*Command
+		allowUnknown bool
+		wantErr      bool
+	}{
+		{
+			name: "no needs",
+			cmds: []*Command{{ID: "a"}, {ID: "a"}},
+		},
+		{
+			name: "ok",
+			cmds: []*Command{{ID: "lint", Needs: []string{"codegen"}}, {ID: "codegen"}, {Needs: []string{"lint"}}},
+		},
+		{
+			name:    "unknown",
+			cmds:    []*Command{{ID: "lint", Needs: []string{"codegen"}}},
+			wantErr: true,
+		},
+		{
+			name:         "allow unknown",
+			cmds:         []*Command{{ID: "lint", Needs: []string{"codegen"}}},
+			allowUnknown: true,
+		},
+		{
+			name:    "duplicate",
+			cmds:    []*Command{{ID: "a"}, {ID: "a"}, {Needs: []string{"a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "self",
+			cmds:    []*Command{{ID: "a", Needs: []string{"a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "cycle",
+			cmds:    []*Command{{ID: "a", Needs: []string{"c"}}, {ID: "b", Needs: []string{"a"}}, {ID: "c", Needs: []string{"b"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkNeeds(tt.cmds, tt.allowUnknown); (err != nil) != tt.wantErr {
+				t.Fatalf("checkNeeds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDagNeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		cmds []*Command
+		want [][]int
+	}{
+		{
+			name: "keep order",
+			cmds: []*Command{{ID: "a"}, {Needs: []string{"a"}}, {}, {}},
+			want: [][]int{nil, {0}, {1}, {2}},
+		},
+		{
+			name: "needs later one",
+			cmds: []*Command{{ID: "lint", Needs: []string{"codegen"}}, {ID: "codegen"}, {}},
+			want: [][]int{{1}, nil, {1}},
+		},
+		{
+			name: "group",
+			cmds: []*Command{{ID: "gen"}, {Group: "lint"}, {Parallel: true}, {Group: "lint"}, {Group: "lint"}, {}, {Needs: []string{"gen"}}},
+			want: [][]int{nil, {0}, {1}, {2}, {2}, {3, 4}, {0}},
+		},
+		{
+			name: "group member needs",
+			cmds: []*Command{{ID: "a"}, {Group: "g"}, {Group: "g", Needs: []string{"a"}}, {Group: "g"}},
+			want: [][]int{nil, {0}, {0}, {2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dagNeeds(tt.cmds); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dagNeeds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_execDAG(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	dir := t.TempDir()
+	fp := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+	cmds := []*Command{
+		// 依赖 codegen 生成的文件
+		{ID: "lint", Needs: []string{"codegen"}, Cmd: "sh", Args: []string{"-c", "test -f " + fp("gen") + " && touch " + fp("lint")}},
+		{ID: "codegen", Cmd: "sh", Args: []string{"-c", "sleep 0.1 && touch " + fp("gen")}},
+		{ID: "vet", Cmd: "sh", Args: []string{"-c", "exit 1"}, AllowFail: true},
+		{ID: "test", Needs: []string{"vet"}, Cmd: "sh", Args: []string{"-c", "touch " + fp("test")}},
+		{ID: "skip", Match: "^commit", Cmd: "sh", Args: []string{"-c", "touch " + fp("skip")}},
+		{Needs: []string{"skip", "lint"}, Cmd: "sh", Args: []string{"-c", "touch " + fp("last")}},
+	}
+	r := &Rule{Concurrency: 2}
+	r.execDAG(context.Background(), cmds, "add .", os.Environ(), nil)
+	for _, name := range []string{"gen", "lint", "test", "last"} {
+		if _, err := os.Stat(fp(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(fp("skip")); err == nil {
+		t.Fatal("skip should not run")
+	}
+}
+
+func TestRule_logPlan(t *testing.T) {
+	bf := &bytes.Buffer{}
+	out, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(bf)
+	log.SetFlags(0)
+	log.SetPrefix("")
+	// 测试中的输出不是终端，本身也不带颜色
+	xcolor.SetColorable(false)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+
+	cmds := []*Command{
+		{ID: "gen", Cmd: "sh"},
+		{ID: "lint", Needs: []string{"gen"}, Cmd: "sh"},
+		{ID: "vet", Needs: []string{"gen"}, Cmd: "sh"},
+		{Needs: []string{"lint", "vet"}, Cmd: "sh"},
+		{}, // 没有 Cmd 的不输出
+	}
+	r := &Rule{}
+	r.logPlan(newDagNodes(cmds), 2)
+	want := []string{
+		"Plan: 3 stages, Concurrency=2",
+		"  Stage 1: gen",
+		"  Stage 2: lint (needs gen), vet (needs gen)",
+		"  Stage 3: #3(sh) (needs lint,vet)",
+	}
+	got := strings.Split(strings.TrimSpace(bf.String()), "\n")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logPlan() = %q, want %q", got, want)
+	}
+}
+
+func TestDagNode_level(t *testing.T) {
+	// 每层 2 个命令，都依赖上一层的 2 个命令，不缓存时计算次数为 2^layers
+	const layers = 64
+	var cmds []*Command
+	for i := 0; i < layers; i++ {
+		for j := 0; j < 2; j++ {
+			c := &Command{ID: fmt.Sprintf("l%d-%d", i, j), Cmd: "sh"}
+			if i > 0 {
+				c.Needs = []string{fmt.Sprintf("l%d-0", i-1), fmt.Sprintf("l%d-1", i-1)}
+			}
+			cmds = append(cmds, c)
+		}
+	}
+	nodes := newDagNodes(cmds)
+	// 第一层的 l0-1 没有 Needs，依赖前一个命令 l0-0
+	if got := nodes[len(nodes)-1].level(); got != layers+1 {
+		t.Errorf("level() = %d, want %d", got, layers+1)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/cli/xcolor"
+
+	"github.com/fsgo/bin-auto-switcher/internal/common"
+)
+
+// hookTask 并行执行的一个命令
+type hookTask struct {
+	cmd    *Command
+	idx    int
+	output hookOutput
+	result *ExecResult
+	done   chan struct{}
+
+	// slot 在结果中的位置
+	slot int
+}
+
+// start 在新的 goroutine 中执行命令，结束后调用 onDone
+// sem 用于限制并发数，为 nil 时由调用方控制
+func (t *hookTask) start(ctx context.Context, env []string, sem chan struct{}, onDone func()) {
+	go func() {
+		defer onDone()
+		if sem != nil {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+		}
+
+		ctx1, cancel := context.WithTimeout(ctx, t.cmd.getTimeout())
+		defer cancel()
+		t.result = t.cmd.run(ctx1, env, t.output.writer(os.Stdout), t.output.writer(os.Stderr))
+		t.result.Name = t.cmd.name(t.idx)
+	}()
+}
+
+// execGroup 并行执行 cmds[start:] 中分组为 group 的命令，返回按照配置顺序的执行结果
+// 各命令的输出先缓存，按照配置的顺序输出，全部执行完成后，调用方再按照 AllowFail 判断是否继续
+func (r *Rule) execGroup(ctx context.Context, group string, cmds []*Command, start int, argsStr string, env []string, mr *ExecResult) []*ExecResult {
+	var results []*ExecResult
+	var tasks []*hookTask
+	for idx := start; idx < len(cmds); idx++ {
+		pc := cmds[idx]
+		if pc.group() != group {
+			continue
+		}
+		run, er := r.prepare(idx, pc, argsStr, mr)
+		if er != nil {
+			results = append(results, er)
+			if er.Fatal() {
+				return results
+			}
+		}
+		if run {
+			tasks = append(tasks, &hookTask{cmd: pc, idx: idx, done: make(chan struct{}), slot: len(results)})
+			results = append(results, nil)
+		}
+	}
+	if len(tasks) == 0 {
+		return results
+	}
+
+	limit := r.concurrency()
+	if r.Trace {
+		log.Printf("%s %q: %d cmds, Concurrency=%d\n", xcolor.CyanString("Group"), group, len(tasks), limit)
+	}
+	begin := time.Now()
+	sem := make(chan struct{}, limit)
+	for _, t := range tasks {
+		t.start(ctx, env, sem, func() { close(t.done) })
+	}
+
+	// 按照顺序输出，不用等待后面的命令执行完成
+	for _, t := range tasks {
+		<-t.done
+		t.output.flush()
+		results[t.slot] = t.result
+	}
+	if r.Trace {
+		log.Printf("%s %q done, Cost=%s\n", xcolor.CyanString("Group"), group, common.CostString(time.Since(begin)))
+	}
+	return results
+}
+
+// concurrency 并行执行的最大并发数
+func (r *Rule) concurrency() int {
+	if r.Concurrency > 0 {
+		return r.Concurrency
+	}
+	return runtime.NumCPU()
+}
+
+// hookOutput 缓存命令的 stdout 和 stderr，flush 时按照写入的顺序输出到各自的目标中
+type hookOutput struct {
+	mux    sync.Mutex
+	chunks []hookChunk
+}
+
+type hookChunk struct {
+	to   io.Writer
+	data []byte
+}
+
+// writer 返回写入到缓存的 Writer，flush 时输出到 to
+func (o *hookOutput) writer(to io.Writer) io.Writer {
+	return &hookWriter{output: o, to: to}
+}
+
+func (o *hookOutput) flush() {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	for _, c := range o.chunks {
+		_, _ = c.to.Write(c.data)
+	}
+	o.chunks = nil
+}
+
+type hookWriter struct {
+	output *hookOutput
+	to     io.Writer
+}
+
+func (w *hookWriter) Write(p []byte) (int, error) {
+	w.output.mux.Lock()
+	defer w.output.mux.Unlock()
+	w.output.chunks = append(w.output.chunks, hookChunk{to: w.to, data: bytes.Clone(p)})
+	return len(p), nil
+}
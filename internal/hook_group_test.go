@@ -0,0 +1,66 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHookOutput(t *testing.T) {
+	var stdout, all bytes.Buffer
+	o := &hookOutput{}
+	w1 := o.writer(&stdout)
+	w2 := o.writer(&all)
+	_, _ = w1.Write([]byte("a"))
+	_, _ = w2.Write([]byte("b"))
+	_, _ = w1.Write([]byte("c"))
+	if stdout.Len() != 0 {
+		t.Fatal("should be buffered before flush")
+	}
+	o.flush()
+	if stdout.String() != "ac" || all.String() != "b" {
+		t.Fatalf("stdout = %q, stderr = %q", stdout.String(), all.String())
+	}
+}
+
+func TestRule_execGroup(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	dir := t.TempDir()
+	// 每个命令创建自己的文件后，等待同组的其他命令创建的文件，只有并行执行时才能都成功
+	wait := `[ -f a ] && [ -f b ] && [ -f c ]`
+	touch := func(name string) *Command {
+		return &Command{
+			Group: "lint",
+			Cmd:   "sh",
+			Args: []string{"-c", "cd " + dir + " && touch " + name + "; i=0; until " + wait +
+				"; do i=$((i+1)); [ $i -gt 200 ] && exit 1; sleep 0.05; done"},
+		}
+	}
+	cmds := []*Command{
+		touch("a"),
+		{Cmd: "sh", Args: []string{"-c", "exit 1"}, AllowFail: true},
+		touch("b"),
+		{Group: "other", Cmd: "sh", Args: []string{"-c", "touch " + filepath.Join(dir, "other")}},
+		touch("c"),
+	}
+	r := &Rule{Concurrency: 3}
+	results := r.execGroup(context.Background(), "lint", cmds, 0, "", os.Environ(), nil)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for _, er := range results {
+		if er.Status != statusOK {
+			t.Fatalf("%s should run concurrently, got %s", er.Name, er.Status)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other")); err == nil {
+		t.Fatal("other group should not run")
+	}
+}
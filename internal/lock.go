@@ -0,0 +1,320 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/xanygo/anygo/cli/xcolor"
+	"github.com/xanygo/anygo/xattr"
+)
+
+// lockFileName 项目的 .bas 目录下记录命令的锁文件
+const lockFileName = "lock.toml"
+
+// lockedBin 锁文件中记录的一个命令
+type lockedBin struct {
+	// Path 命令的绝对路径
+	Path string `toml:"Path"`
+
+	// Version 查看版本的命令的输出，如 "go version go1.22.5 linux/amd64"
+	Version string `toml:"Version"`
+
+	// SHA256 命令文件的 sha256
+	SHA256 string `toml:"SHA256"`
+}
+
+// lockVersionArgs 查看命令版本的参数，未列出的使用 --version
+var lockVersionArgs = map[string][]string{
+	"go":    {"version"},
+	"java":  {"-version"},
+	"javac": {"-version"},
+}
+
+// cmdLock 解析当前目录下命令 name 使用的规则(包括 Spec 的修改)，并将命令记录到 .bas/lock.toml
+// name 为空时，记录当前项目中所有有配置(.bas/{name}.toml)的命令，被其他配置 Include 的文件除外，
+// 此时某个命令解析失败，不影响其他命令的记录
+func cmdLock(name string) error {
+	names := []string{name}
+	if name == "" {
+		files, err := allConfigFiles("")
+		if err != nil {
+			return err
+		}
+		names = nil
+		for _, f := range files {
+			if filepath.Dir(f.path) != configDir() && !slices.Contains(names, f.name) {
+				names = append(names, f.name)
+			}
+		}
+		if len(names) == 0 {
+			return errors.New("no .bas/{name}.toml found in current dir and its parent dirs, please use 'lock {name}'")
+		}
+	}
+
+	fp, err := lockFilePath()
+	if err != nil {
+		return err
+	}
+	locks, err := loadLocks(fp)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if locks == nil {
+		locks = map[string]*lockedBin{}
+	}
+	var failed []string
+	for _, n := range names {
+		lb, err := resolveLockedBin(n)
+		if err != nil {
+			if name != "" {
+				return fmt.Errorf("lock %s: %w", n, err)
+			}
+			failed = append(failed, n)
+			fmt.Println(xcolor.RedString("[Error]"), "lock", n+":", err)
+			continue
+		}
+		locks[n] = lb
+		fmt.Printf("[Locked] %-8s %s\n         %s\n         sha256:%s\n", n, lb.Path, lb.Version, lb.SHA256)
+	}
+	if len(failed) < len(names) {
+		if err = writeLocks(fp, locks); err != nil {
+			return err
+		}
+		fmt.Println("write", fp)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("lock %q failed", failed)
+	}
+	return nil
+}
+
+// resolveLockedBin 解析命令 name 当前使用的可执行文件
+func resolveLockedBin(name string) (*lockedBin, error) {
+	cfg, err := LoadConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := cfg.Rule()
+	if err != nil {
+		return nil, err
+	}
+	if err = rule.BeforeExec(context.Background(), name); err != nil {
+		return nil, err
+	}
+	fp, err := exec.LookPath(rule.Cmd)
+	if err != nil {
+		return nil, err
+	}
+	if fp, err = filepath.Abs(fp); err != nil {
+		return nil, err
+	}
+	sum, err := fileSHA256(fp)
+	if err != nil {
+		return nil, err
+	}
+	return &lockedBin{
+		Path:    fp,
+		Version: binVersion(name, fp, rule.Env),
+		SHA256:  sum,
+	}, nil
+}
+
+// binVersion 执行命令查看其版本，返回输出的第一行
+func binVersion(name string, fp string, env []string) string {
+	args, ok := lockVersionArgs[name]
+	if !ok {
+		args = []string{"--version"}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, fp, args...)
+	cmd.Env = dedupEnv(caseInsensitiveEnv, append(os.Environ(), append(env, envKey("NoHook")+"=true")...))
+	out, err := cmd.CombinedOutput()
+	line := firstLine(out)
+	if err != nil && line == "" {
+		return "unknown: " + err.Error()
+	}
+	return line
+}
+
+func fileSHA256(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lockFilePath 锁文件的路径，和执行命令时一样，优先使用当前目录以及上级目录中已有的 .bas/lock.toml，
+// 没有时为最近的 .bas 目录下的 lock.toml，都没有 .bas 目录时，为当前目录下的 .bas/lock.toml
+func lockFilePath() (string, error) {
+	if fp, err := findLockFile(); fp != "" || err != nil {
+		return fp, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for dir := wd; ; {
+		if st, err := os.Stat(filepath.Join(dir, ".bas")); err == nil && st.IsDir() {
+			return filepath.Join(dir, ".bas", lockFileName), nil
+		}
+		next := filepath.Dir(dir)
+		if next == dir {
+			break
+		}
+		dir = next
+	}
+	return filepath.Join(wd, ".bas", lockFileName), nil
+}
+
+func loadLocks(fp string) (map[string]*lockedBin, error) {
+	content, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	locks := map[string]*lockedBin{}
+	if err = toml.Unmarshal(content, &locks); err != nil {
+		return nil, fmt.Errorf("parser %q: %w", fp, err)
+	}
+	return locks, nil
+}
+
+func writeLocks(fp string, locks map[string]*lockedBin) error {
+	bf := &bytes.Buffer{}
+	bf.WriteString("# Generated by 'bas lock', do not edit.\n\n")
+	for _, name := range slices.Sorted(maps.Keys(locks)) {
+		if err := toml.NewEncoder(bf).Encode(map[string]*lockedBin{name: locks[name]}); err != nil {
+			return err
+		}
+		bf.WriteString("\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(fp, bf.Bytes(), 0644)
+}
+
+// findLockFile 查找当前目录以及上级目录中的 .bas/lock.toml，没有时返回空
+// 在解析规则时调用，查找过的路径会记录到规则缓存的依赖中，使用缓存时不必再查找
+func findLockFile() (string, error) {
+	fp, err := findFileUpper(filepath.Join(".bas", lockFileName), 128)
+	if errors.Is(err, errFileNotFound) {
+		return "", nil
+	}
+	return fp, err
+}
+
+// verifyLock 检查命令 name 当前使用的可执行文件和 .bas/lock.toml 中记录的是否一致，
+// 不一致时，Strict 模式下返回错误，否则打印警告
+func (r *Rule) verifyLock(name string) error {
+	fp := r.lockFile
+	if fp == "" {
+		return nil
+	}
+	locks, err := loadLocks(fp)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	lb, ok := locks[name]
+	if !ok {
+		return nil
+	}
+	err = lb.verify(r.Cmd)
+	if err == nil {
+		if r.Trace {
+			log.Printf("%s matches %q\n", r.Cmd, fp)
+		}
+		return nil
+	}
+	err = fmt.Errorf("%w, lock file: %q, hint: run 'bas lock %s' to update it", err, fp, name)
+	if r.Strict {
+		return err
+	}
+	log.Println(xcolor.YellowString("[Warning] " + err.Error()))
+	return nil
+}
+
+// verify 检查命令 cmd 是否和记录的一致，以 sha256 为准，
+// 路径不同但内容相同的也认为是一致的，如不同机器上安装在不同的目录
+func (lb *lockedBin) verify(cmd string) error {
+	fp, err := exec.LookPath(cmd)
+	if err != nil {
+		return err
+	}
+	if fp, err = filepath.Abs(fp); err != nil {
+		return err
+	}
+	sum, err := cachedSHA256(fp)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(sum, lb.SHA256) {
+		return nil
+	}
+	if fp != lb.Path {
+		return fmt.Errorf("%q is used, but %q (%s) is locked", fp, lb.Path, lb.Version)
+	}
+	return fmt.Errorf("sha256 of %q is %s, but %s (%s) is locked", fp, sum, lb.SHA256, lb.Version)
+}
+
+// fileSum 缓存的文件的 sha256
+type fileSum struct {
+	File   cacheDep
+	SHA256 string
+}
+
+// cachedSHA256 文件 fp 的 sha256，文件的大小和修改时间都没有变化时，使用缓存的值，
+// 避免每次执行命令时都读取整个可执行文件
+func cachedSHA256(fp string) (string, error) {
+	h := sha256.Sum256([]byte(fp))
+	cp := filepath.Join(xattr.DataDir(), "lock_sum", hex.EncodeToString(h[:])+".json")
+	var fs fileSum
+	if content, err := os.ReadFile(cp); err == nil && json.Unmarshal(content, &fs) == nil &&
+		fs.File.Path == fp && !fs.File.Missing && !fs.File.changed() {
+		return fs.SHA256, nil
+	}
+	dep := newCacheDep(fp)
+	sum, err := fileSHA256(fp)
+	if err != nil {
+		return "", err
+	}
+	// 计算期间文件有变化时，不缓存
+	if newCacheDep(fp) != dep {
+		return sum, nil
+	}
+	content, err := json.Marshal(fileSum{File: dep, SHA256: sum})
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(cp), 0755); err == nil {
+		_ = os.WriteFile(cp, content, 0644)
+	}
+	return sum, nil
+}
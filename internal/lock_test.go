@@ -0,0 +1,268 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xattr"
+)
+
+// setDataDir 修改 xattr 的数据目录，测试结束后恢复
+func setDataDir(t *testing.T, dir string) {
+	old := xattr.DataDir()
+	xattr.SetDataDir(dir)
+	t.Cleanup(func() {
+		xattr.SetDataDir(old)
+	})
+}
+
+func TestLocks(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), ".bas", lockFileName)
+	locks := map[string]*lockedBin{
+		"go":   {Path: "/sdk/go1.22.5/bin/go", Version: "go version go1.22.5 linux/amd64", SHA256: "abc"},
+		"node": {Path: "/nvm/v20.11.0/bin/node", Version: "v20.11.0", SHA256: "def"},
+	}
+	if err := writeLocks(fp, locks); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadLocks(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, locks) {
+		t.Errorf("loadLocks() = %+v, want %+v", got, locks)
+	}
+}
+
+func TestCachedSHA256(t *testing.T) {
+	setDataDir(t, t.TempDir())
+	fp := filepath.Join(t.TempDir(), "bin")
+	writeFile(t, fp, "v1")
+	want, err := fileSHA256(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if got, err := cachedSHA256(fp); err != nil || got != want {
+			t.Fatalf("cachedSHA256() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	// 大小和修改时间都没有变化时，使用缓存的值
+	st, err := os.Stat(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fp, "v2")
+	if err = os.Chtimes(fp, st.ModTime(), st.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cachedSHA256(fp); got != want {
+		t.Errorf("cachedSHA256() = %q, want the cached %q", got, want)
+	}
+
+	// 修改时间变化后，重新计算
+	mt := st.ModTime().Add(time.Second)
+	if err = os.Chtimes(fp, mt, mt); err != nil {
+		t.Fatal(err)
+	}
+	want2, _ := fileSHA256(fp)
+	if got, _ := cachedSHA256(fp); got != want2 || got == want {
+		t.Errorf("cachedSHA256() = %q, want %q", got, want2)
+	}
+}
+
+func TestRule_verifyLock(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	setDataDir(t, t.TempDir())
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "x")
+	writeExec(t, bin)
+	sum, err := fileSHA256(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := filepath.Join(dir, ".bas", lockFileName)
+	if err = writeLocks(fp, map[string]*lockedBin{"x": {Path: bin, SHA256: sum}}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Rule{Cmd: bin, Strict: true}
+	if err = r.verifyLock("x"); err != nil {
+		t.Fatalf("verifyLock() without lock file = %v", err)
+	}
+	r.lockFile = fp
+	if err = r.verifyLock("x"); err != nil {
+		t.Fatalf("verifyLock() = %v", err)
+	}
+	if err = r.verifyLock("y"); err != nil {
+		t.Fatalf("verifyLock() not locked = %v", err)
+	}
+
+	r.Cmd = filepath.Join(dir, "other")
+	writeFile(t, r.Cmd, "#!/bin/sh\necho other\n")
+	if err = os.Chmod(r.Cmd, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.verifyLock("x"); err == nil || !strings.Contains(err.Error(), "is locked") {
+		t.Fatalf("verifyLock() = %v, want locked error", err)
+	}
+	r.Strict = false
+	if err = r.verifyLock("x"); err != nil {
+		t.Fatalf("verifyLock() not Strict = %v", err)
+	}
+}
+
+func TestLockFilePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string // 相对于项目目录的文件，在 sub 目录中执行
+		want  string
+	}{
+		{
+			name:  "existing lock file",
+			files: []string{".bas/lock.toml", "sub/.bas/node.toml"},
+			want:  ".bas/lock.toml",
+		},
+		{
+			name:  "nearest .bas dir",
+			files: []string{".bas/go.toml", "sub/.bas/node.toml"},
+			want:  "sub/.bas/lock.toml",
+		},
+		{
+			name:  "parent .bas dir",
+			files: []string{".bas/go.toml", "sub/main.go"},
+			want:  ".bas/lock.toml",
+		},
+		{
+			name:  "no .bas dir",
+			files: []string{"sub/main.go"},
+			want:  "sub/.bas/lock.toml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			for _, name := range tt.files {
+				writeFile(t, filepath.Join(project, name), "")
+			}
+			t.Chdir(filepath.Join(project, "sub"))
+			got, err := lockFilePath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(project, tt.want); got != want {
+				t.Errorf("lockFilePath() = %q, want %q", got, want)
+			}
+			// 执行命令时检查的，和写入的是同一个文件
+			found, err := findLockFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != "" && found != got {
+				t.Errorf("findLockFile() = %q, want %q", found, got)
+			}
+		})
+	}
+}
+
+func TestCmdLock(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	setHomeDir(t, t.TempDir())
+	setDataDir(t, t.TempDir())
+	binDir := t.TempDir()
+	bin := filepath.Join(binDir, "lockedtool")
+	writeFile(t, bin, "#!/bin/sh\necho lockedtool 1.0\n")
+	if err := os.Chmod(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+
+	project := t.TempDir()
+	// common.toml 被 lockedtool.toml 引入，不是命令的配置，全局的配置也不记录
+	writeFile(t, filepath.Join(project, ".bas", "lockedtool.toml"), "Include = [\"common.toml\"]\n[[Rules]]\n")
+	writeFile(t, filepath.Join(project, ".bas", "common.toml"), "# shared by all commands\n")
+	writeFile(t, filepath.Join(configDir(), "git.toml"), "[[Rules]]\n")
+	writeFile(t, filepath.Join(project, "sub", "main.go"), "")
+	t.Chdir(filepath.Join(project, "sub"))
+
+	if err := cmdLock(""); err != nil {
+		t.Fatalf("cmdLock() = %v", err)
+	}
+	fp := filepath.Join(project, ".bas", lockFileName)
+	sum, err := fileSHA256(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*lockedBin{
+		"lockedtool": {Path: bin, Version: "lockedtool 1.0", SHA256: sum},
+	}
+	if got, err := loadLocks(fp); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("loadLocks() = %+v, %v, want %+v", got, err, want)
+	}
+
+	// 一个命令失败时，其他命令仍然记录
+	writeFile(t, filepath.Join(project, ".bas", "brokentool.toml"), "[[Rules]]\n")
+	if err = os.Remove(fp); err != nil {
+		t.Fatal(err)
+	}
+	if err = cmdLock(""); err == nil || !strings.Contains(err.Error(), "brokentool") {
+		t.Fatalf("cmdLock() = %v, want brokentool failed", err)
+	}
+	if got, err := loadLocks(fp); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("loadLocks() = %+v, %v, want %+v", got, err, want)
+	}
+	if err = cmdLock("brokentool"); err == nil || !strings.Contains(err.Error(), "lock brokentool:") {
+		t.Fatalf("cmdLock(brokentool) = %v, want error", err)
+	}
+}
+
+func TestResolveLockedBin(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	setHomeDir(t, t.TempDir())
+	setDataDir(t, t.TempDir())
+	binDir := t.TempDir()
+	t.Setenv("PATH", binDir)
+	bin := filepath.Join(binDir, "resolvedtool")
+	writeFile(t, bin, "#!/bin/sh\n[ \"$1\" = \"version\" ] && echo resolvedtool 2.0\nexit 1\n")
+	if err := os.Chmod(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, ".bas", "resolvedtool.toml"), "[[Rules]]\nCmd = \""+bin+"\"\n")
+	t.Chdir(project)
+
+	lb, err := resolveLockedBin("resolvedtool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 没有在 lockVersionArgs 中的，使用 --version，执行失败并且没有输出
+	if lb.Path != bin || !strings.HasPrefix(lb.Version, "unknown: ") {
+		t.Errorf("resolveLockedBin() = %+v", lb)
+	}
+
+	lockVersionArgs["resolvedtool"] = []string{"version"}
+	t.Cleanup(func() {
+		delete(lockVersionArgs, "resolvedtool")
+	})
+	if lb, err = resolveLockedBin("resolvedtool"); err != nil || lb.Version != "resolvedtool 2.0" {
+		t.Errorf("resolveLockedBin() = %+v, %v", lb, err)
+	}
+
+	if _, err = resolveLockedBin("notexists"); err == nil {
+		t.Error("resolveLockedBin(notexists) = nil, want error")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/xanygo/anygo/xcfg"
+)
+
+// Merge 将 b merge 到 c，b 为更靠近当前目录的配置
+//
+// Trace、Skip、Strict: b 中明确配置了时使用 b 的，否则保持 c 的，详见 mergeBool
+// Spec: 按 key 合并，b 的值覆盖 c 的
+// Rules: 和 c 中 Dir、When 都相同的规则合并到一起(详见 Rule.Merge)，其他的追加到后面
+func (c *Config) Merge(b *Config) {
+	c.Trace = mergeBool(c.Trace, b.Trace, b.bools.Trace, &c.bools.Trace)
+	c.Skip = mergeBool(c.Skip, b.Skip, b.bools.Skip, &c.bools.Skip)
+	c.Strict = mergeBool(c.Strict, b.Strict, b.bools.Strict, &c.bools.Strict)
+	c.Spec = mergeSpec(c.Spec, b.Spec)
+
+	for _, rb := range b.Rules {
+		key := rb.mergeKey()
+		idx := slices.IndexFunc(c.Rules, func(r *Rule) bool {
+			return r.mergeKey() == key
+		})
+		if idx == -1 {
+			c.Rules = append(c.Rules, rb)
+			continue
+		}
+		c.Rules[idx].Merge(rb)
+	}
+}
+
+// mergeKey 用于判断两个规则是否是同一条规则，Dir(格式化后的) 和 When 都相同即为同一条
+func (r *Rule) mergeKey() string {
+	dirs := make([]string, 0, len(r.Dir))
+	for _, d := range r.Dir {
+		dirs = append(dirs, formatDir(d))
+	}
+	when := make([]string, 0, len(r.When))
+	for _, w := range r.When {
+		when = append(when, strings.TrimSpace(string(w)))
+	}
+	return strings.Join(dirs, "\n") + "\x00" + strings.Join(when, "\n")
+}
+
+// Merge 将 b merge 到 r
+//
+// Cmd、Args、ArgsAppend、Version、Concurrency: b 中的值不为空时，使用 b 的
+// Skip、Trace、Strict: b 中明确配置了时使用 b 的，否则保持 r 的
+// Env: 合并，同名的使用 b 的
+// Pre、Post: 追加到后面，若 b 中的 Command 和 r 中的 Command 的 ID 相同，则替换 r 中的
+// Spec、Alias: 按 key 合并
+// Rewrite: 追加到后面
+func (r *Rule) Merge(b *Rule) {
+	if b.Cmd != "" {
+		r.Cmd = b.Cmd
+	}
+	r.Skip = mergeBool(r.Skip, b.Skip, b.bools.Skip, &r.bools.Skip)
+	r.Trace = mergeBool(r.Trace, b.Trace, b.bools.Trace, &r.bools.Trace)
+	r.Strict = mergeBool(r.Strict, b.Strict, b.bools.Strict, &r.bools.Strict)
+	if b.Version != "" {
+		r.Version = b.Version
+	}
+	if len(b.Args) > 0 {
+		r.Args = b.Args
+	}
+	if len(b.ArgsAppend) > 0 {
+		r.ArgsAppend = b.ArgsAppend
+	}
+	if len(b.Alias) > 0 {
+		r.Alias = maps.Clone(r.Alias)
+		if r.Alias == nil {
+			r.Alias = make(map[string]string, len(b.Alias))
+		}
+		maps.Copy(r.Alias, b.Alias)
+	}
+	r.Rewrite = slices.Concat(r.Rewrite, b.Rewrite)
+	if b.Concurrency > 0 {
+		r.Concurrency = b.Concurrency
+	}
+	if len(b.Env) > 0 {
+		r.Env = dedupEnv(caseInsensitiveEnv, append(slices.Clone(r.Env), b.Env...))
+	}
+	r.Pre = mergeCommands(r.Pre, b.Pre)
+	r.Post = mergeCommands(r.Post, b.Post)
+	r.Spec = mergeSpec(r.Spec, b.Spec)
+}
+
+// boolFields 配置文件中明确配置了的 bool 字段，用于合并时区分未配置和配置为 false
+type boolFields struct {
+	Trace  *bool
+	Skip   *bool
+	Strict *bool
+}
+
+// configBools 和 Config 对应，只解析其中的 bool 字段
+type configBools struct {
+	boolFields
+	Rules []boolFields
+}
+
+// parseBools 解析配置文件中明确配置了的 bool 字段，保存到 cfg 和其 Rules 中
+func parseBools(fp string, cfg *Config) error {
+	var cb *configBools
+	if err := xcfg.Parse(fp, &cb); err != nil {
+		return err
+	}
+	cfg.bools = cb.boolFields
+	for idx, rb := range cb.Rules {
+		if idx < len(cfg.Rules) {
+			cfg.Rules[idx].bools = rb
+		}
+	}
+	return nil
+}
+
+// mergeBool 合并 bool 字段：set 不为空时(b 中明确配置了)，使用 b 的值，
+// 这样更靠近当前目录的配置可以关闭全局配置中打开的 Trace 等；
+// 否则(如不是从配置文件解析的)，任意一个为 true 即为 true
+// to: 合并后的结果中记录是否明确配置了，以便继续往上合并
+func mergeBool(a bool, b bool, set *bool, to **bool) bool {
+	if set == nil {
+		return a || b
+	}
+	*to = set
+	return *set
+}
+
+func mergeCommands(a []*Command, b []*Command) []*Command {
+	if len(b) == 0 {
+		return a
+	}
+	result := slices.Clone(a)
+	for _, cb := range b {
+		if cb.ID != "" {
+			idx := slices.IndexFunc(result, func(ca *Command) bool {
+				return ca.ID == cb.ID
+			})
+			if idx != -1 {
+				result[idx] = cb
+				continue
+			}
+		}
+		result = append(result, cb)
+	}
+	return result
+}
+
+func mergeSpec(a map[string]any, b map[string]any) map[string]any {
+	if len(b) == 0 {
+		return a
+	}
+	result := maps.Clone(a)
+	if result == nil {
+		result = make(map[string]any, len(b))
+	}
+	maps.Copy(result, b)
+	return result
+}
@@ -0,0 +1,153 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_Merge(t *testing.T) {
+	global := &Config{
+		Rules: []*Rule{
+			{
+				Cmd:   "/usr/local/bin/git",
+				Env:   []string{"K1=v1", "K2=v2"},
+				Alias: map[string]string{"st": "status", "co": "checkout"},
+				Pre: []*Command{
+					{ID: "fmt", Cmd: "gofmt"},
+					{Cmd: "echo"},
+				},
+			},
+		},
+	}
+	repo := &Config{
+		Trace: true,
+		Rules: []*Rule{
+			{
+				Env:   []string{"K2=v3"},
+				Alias: map[string]string{"st": "status -sb"},
+				Pre: []*Command{
+					{ID: "fmt", Cmd: "gorgeous"},
+					{ID: "lint", Cmd: "staticcheck"},
+				},
+			},
+			{
+				Dir: []string{"~/workspace/legacy"},
+				Cmd: "git2",
+			},
+		},
+	}
+	global.Merge(repo)
+
+	if !global.Trace {
+		t.Errorf("Trace = false, want true")
+	}
+	if len(global.Rules) != 2 {
+		t.Fatalf("len(Rules) = %d, want 2", len(global.Rules))
+	}
+	r := global.Rules[0]
+	if r.Cmd != "/usr/local/bin/git" {
+		t.Errorf("Cmd = %q", r.Cmd)
+	}
+	if want := []string{"K1=v1", "K2=v3"}; !reflect.DeepEqual(r.Env, want) {
+		t.Errorf("Env = %q, want %q", r.Env, want)
+	}
+	if want := map[string]string{"st": "status -sb", "co": "checkout"}; !reflect.DeepEqual(r.Alias, want) {
+		t.Errorf("Alias = %q, want %q", r.Alias, want)
+	}
+	var cmds []string
+	for _, c := range r.Pre {
+		cmds = append(cmds, c.Cmd)
+	}
+	if want := []string{"gorgeous", "echo", "staticcheck"}; !reflect.DeepEqual(cmds, want) {
+		t.Errorf("Pre = %q, want %q", cmds, want)
+	}
+	if global.Rules[1].Cmd != "git2" {
+		t.Errorf("Rules[1].Cmd = %q", global.Rules[1].Cmd)
+	}
+}
+
+// setHomeDir 测试中使用 dir 作为 home 目录，结束后恢复
+func setHomeDir(t *testing.T, dir string) {
+	old := homeDir
+	homeDir = dir
+	t.Cleanup(func() {
+		homeDir = old
+	})
+}
+
+// writeFile 写入测试用的文件，会自动创建所在的目录
+func writeFile(t *testing.T, fp string, content string) {
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig_Layers(t *testing.T) {
+	const name = "bas-test-layers"
+	home := t.TempDir()
+	setHomeDir(t, home)
+	repo := filepath.Join(home, "repo")
+	sub := filepath.Join(repo, "sub")
+
+	writeFile(t, globalConfigPath(name), `
+Trace = true
+[[Rules]]
+Cmd = "/bin/echo"
+Env = ["K1=v1"]
+[[Rules]]
+Dir = ["~/repo/sub"]
+Cmd = "/bin/echo"
+Strict = true
+`)
+	writeFile(t, filepath.Join(repo, ".bas", name+".toml"), `
+[[Rules]]
+Env = ["K2=v2"]
+`)
+	writeFile(t, filepath.Join(sub, ".bas", name+".toml"), `
+Trace = false
+[[Rules]]
+Dir = ["`+filepath.ToSlash(sub)+`/"]
+Strict = false
+Args = ["sub"]
+`)
+	t.Chdir(sub)
+
+	cfg, err := LoadConfig(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.fileNames) != 3 {
+		t.Fatalf("fileNames = %q, want 3 files", cfg.fileNames)
+	}
+	if cfg.Trace != enableTrace {
+		t.Errorf("Trace = %v, want turned off by the nearer config", cfg.Trace)
+	}
+	if len(cfg.Rules) != 2 {
+		t.Fatalf("len(Rules) = %d, want 2", len(cfg.Rules))
+	}
+	if want := []string{"K1=v1", "K2=v2"}; !reflect.DeepEqual(cfg.Rules[0].Env, want) {
+		t.Errorf("Rules[0].Env = %q, want %q", cfg.Rules[0].Env, want)
+	}
+	r := cfg.Rules[1]
+	if r.Strict {
+		t.Errorf("Rules[1].Strict = true, want turned off by the nearer config")
+	}
+	if want := []string{"sub"}; !reflect.DeepEqual(r.Args, want) {
+		t.Errorf("Rules[1].Args = %q, want %q", r.Args, want)
+	}
+	using, err := cfg.Rule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if using != r {
+		t.Errorf("Rule() = %v, want Rules[1]", using.Dir)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ArgsRewrite 使用正则改写命令的参数
+type ArgsRewrite struct {
+	// Match 正则，匹配以空格连接的参数，如 "^push$"
+	Match string
+
+	// Replace 替换的内容，支持 $1 等引用 Match 中的分组，如 "push --force-with-lease"
+	Replace string
+
+	reg *regexp.Regexp
+}
+
+func (ar *ArgsRewrite) compile() (*regexp.Regexp, error) {
+	if ar.reg != nil {
+		return ar.reg, nil
+	}
+	reg, err := regexp.Compile(ar.Match)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Match %q: %w", ar.Match, err)
+	}
+	ar.reg = reg
+	return reg, nil
+}
+
+// rewriteArgs 依次执行 Alias 和 Rewrite，返回改写后的参数，不包含 Args 和 ArgsAppend
+// 这些参数也用于 Pre、Post 的 Match 和环境变量 BAS_ARGS
+func (r *Rule) rewriteArgs(args []string) ([]string, error) {
+	if len(args) > 0 && r.Alias[args[0]] != "" {
+		alias, err := splitArgs(r.Alias[args[0]])
+		if err != nil {
+			return nil, fmt.Errorf("Alias %q: %w", args[0], err)
+		}
+		if r.Trace {
+			log.Printf("Alias %q -> %q\n", args[0], alias)
+		}
+		args = append(alias, args[1:]...)
+	}
+	for idx, ar := range r.Rewrite {
+		reg, err := ar.compile()
+		if err != nil {
+			return nil, fmt.Errorf("Rewrite[%d]: %w", idx, err)
+		}
+		str := joinArgs(args)
+		if !reg.MatchString(str) {
+			continue
+		}
+		str = reg.ReplaceAllString(str, ar.Replace)
+		next, err := splitArgs(str)
+		if err != nil {
+			return nil, fmt.Errorf("Rewrite[%d]: %w", idx, err)
+		}
+		if r.Trace {
+			log.Printf("Rewrite[%d] %q -> %q\n", idx, args, next)
+		}
+		args = next
+	}
+	return args, nil
+}
+
+// cmdArgs 主命令最终的参数：Args + 改写后的参数 + ArgsAppend
+func (r *Rule) cmdArgs(args []string) []string {
+	return slices.Concat(r.Args, args, r.ArgsAppend)
+}
+
+// checkArgs 检查 Alias 和 Rewrite 的配置
+func (r *Rule) checkArgs() error {
+	for name, value := range r.Alias {
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("Alias: invalid name %q", name)
+		}
+		if args, err := splitArgs(value); err != nil {
+			return fmt.Errorf("Alias %q: %w", name, err)
+		} else if len(args) == 0 {
+			return fmt.Errorf("Alias %q: is empty", name)
+		}
+	}
+	for idx, ar := range r.Rewrite {
+		if _, err := ar.compile(); err != nil {
+			return fmt.Errorf("Rewrite[%d]: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+// splitArgs 按照空格切分参数，支持单引号、双引号和反斜杠转义，如 `log --format="%h %s"`
+func splitArgs(str string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	var hasArg, escape bool
+	for _, c := range str {
+		switch {
+		case escape:
+			cur.WriteRune(c)
+			escape = false
+		case c == '\\' && quote != '\'':
+			escape = true
+			hasArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			hasArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if hasArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				hasArg = false
+			}
+		default:
+			cur.WriteRune(c)
+			hasArg = true
+		}
+	}
+	if escape || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", str)
+	}
+	if hasArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// joinArgs 以空格连接参数，包含空格、引号等的参数使用单引号，splitArgs 可以还原
+func joinArgs(args []string) string {
+	items := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\") {
+			items = append(items, arg)
+			continue
+		}
+		items = append(items, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(items, " ")
+}
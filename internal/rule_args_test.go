@@ -0,0 +1,85 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    []string
+		wantErr bool
+	}{
+		{str: "status -sb", want: []string{"status", "-sb"}},
+		{str: `log  --format="%h %s"`, want: []string{"log", "--format=%h %s"}},
+		{str: `commit -m 'it'\''s' ""`, want: []string{"commit", "-m", "it's", ""}},
+		{str: `a\ b`, want: []string{"a b"}},
+		{str: "", want: nil},
+		{str: `log "a`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, err := splitArgs(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitArgs() = %q, want %q", got, tt.want)
+			}
+			if err == nil {
+				back, _ := splitArgs(joinArgs(got))
+				if !reflect.DeepEqual(back, got) {
+					t.Fatalf("joinArgs() = %q, split back = %q", joinArgs(got), back)
+				}
+			}
+		})
+	}
+}
+
+func TestRule_rewriteArgs(t *testing.T) {
+	r := &Rule{
+		Args:       []string{"-c", "color.ui=always"},
+		ArgsAppend: []string{"-v"},
+		Alias: map[string]string{
+			"st": "status -sb",
+			"lg": `log --format="%h %s"`,
+		},
+		Rewrite: []*ArgsRewrite{
+			{Match: "^push$", Replace: "push --force-with-lease"},
+			{Match: "^$", Replace: "status"},
+		},
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"st", "."}, want: []string{"status", "-sb", "."}},
+		{args: []string{"lg", "-3"}, want: []string{"log", "--format=%h %s", "-3"}},
+		{args: []string{"push"}, want: []string{"push", "--force-with-lease"}},
+		{args: []string{"push", "origin"}, want: []string{"push", "origin"}},
+		{args: []string{"commit", "-m", "a b"}, want: []string{"commit", "-m", "a b"}},
+		{args: nil, want: []string{"status"}},
+	}
+	for _, tt := range tests {
+		got, err := r.rewriteArgs(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("rewriteArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+	want := []string{"-c", "color.ui=always", "status", "-v"}
+	if got := r.cmdArgs([]string{"status"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("cmdArgs() = %q, want %q", got, want)
+	}
+
+	r.Rewrite = append(r.Rewrite, &ArgsRewrite{Match: "("})
+	if err := r.checkArgs(); err == nil {
+		t.Fatal("should fail with invalid Match")
+	}
+}
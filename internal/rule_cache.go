@@ -0,0 +1,294 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xanygo/anygo/xattr"
+)
+
+// ruleCacheFormat 缓存文件格式的版本，格式变化时需要修改
+const ruleCacheFormat = 3
+
+// ruleCache 缓存的已解析(包括 Spec 的修改)的规则
+// 当前目录、环境变量相同，并且解析时查看过的文件、目录、环境变量都没有变化时，直接使用，
+// 避免每次执行都加载配置文件、查找 go.mod、读取 PATH 中各个命令的 buildinfo 等
+type ruleCache struct {
+	Format int
+
+	// Deps 解析规则时查看过的文件和目录
+	Deps []cacheDep
+
+	// Env 解析规则时读取过的环境变量(不包括计算缓存 key 时已使用的)及其值
+	Env map[string]string `json:",omitempty"`
+
+	Rule      *Rule
+	Matched   string   `json:",omitempty"`
+	Decisions []string `json:",omitempty"`
+	LockFile  string   `json:",omitempty"`
+}
+
+// cacheDep 缓存所依赖的一个文件或目录
+type cacheDep struct {
+	Path    string
+	ModTime int64 `json:",omitempty"` // UnixNano
+	Size    int64 `json:",omitempty"`
+	Missing bool  `json:",omitempty"`
+}
+
+func newCacheDep(fp string) cacheDep {
+	st, err := os.Stat(fp)
+	if err != nil {
+		return cacheDep{Path: fp, Missing: true}
+	}
+	return cacheDep{Path: fp, ModTime: st.ModTime().UnixNano(), Size: st.Size()}
+}
+
+// changed 文件或目录是否已经变化，包括新创建和被删除
+func (d cacheDep) changed() bool {
+	return newCacheDep(d.Path) != d
+}
+
+// depRecorder 记录解析规则过程中查看过的文件和目录
+type depRecorder struct {
+	mux  sync.Mutex
+	deps map[string]cacheDep
+	env  map[string]string
+
+	// noCache 不能缓存的原因，如规则的 When 中有 exec 条件
+	noCache string
+}
+
+// cacheRecorder 仅在需要写规则缓存时不为 nil
+var cacheRecorder *depRecorder
+
+// watchPath 记录解析规则依赖的文件或目录(可以不存在)，其变化后缓存失效
+func watchPath(fp string) {
+	dr := cacheRecorder
+	if dr == nil {
+		return
+	}
+	if p, err := filepath.Abs(fp); err == nil {
+		fp = p
+	}
+	dr.mux.Lock()
+	defer dr.mux.Unlock()
+	if _, ok := dr.deps[fp]; !ok {
+		dr.deps[fp] = newCacheDep(fp)
+	}
+}
+
+// getenv 读取环境变量，并记录为解析规则的依赖，其值变化后缓存失效
+func getenv(key string) string {
+	value := os.Getenv(key)
+	dr := cacheRecorder
+	if dr == nil || ruleCacheEnvKey(key) {
+		return value
+	}
+	dr.mux.Lock()
+	defer dr.mux.Unlock()
+	dr.env[key] = value
+	return value
+}
+
+// configEnvReg 配置文件中引用的环境变量，如 {env.NAME}、{env.NAME|default} 以及模板中的 {{ env "NAME" }}
+var configEnvReg = regexp.MustCompile(`\{env\.([A-Za-z0-9_]+)|\benv\s+"([^"]+)"`)
+
+// watchConfigEnv 记录配置文件 fp 中引用的环境变量
+func watchConfigEnv(fp string) {
+	if cacheRecorder == nil {
+		return
+	}
+	content, err := os.ReadFile(fp)
+	if err != nil {
+		return
+	}
+	for _, m := range configEnvReg.FindAllSubmatch(content, -1) {
+		getenv(string(append(m[1], m[2]...)))
+	}
+}
+
+// disableRuleCache 当前解析的规则不能缓存，如依赖命令的执行结果
+func disableRuleCache(reason string) {
+	dr := cacheRecorder
+	if dr == nil {
+		return
+	}
+	dr.mux.Lock()
+	defer dr.mux.Unlock()
+	if dr.noCache == "" {
+		dr.noCache = reason
+	}
+}
+
+// ruleCacheEnv 除了 BAS_ 开头的之外，计算缓存 key 时使用的环境变量，
+// 其他的环境变量，只有在解析规则时读取了(见 getenv)，才会记录在缓存中
+var ruleCacheEnv = []string{"bas", "PATH", "Path", "HOME", "XDG_CONFIG_HOME", "USERPROFILE", "AppData"}
+
+// ruleCacheVolatileEnv 计算缓存 key 时忽略的 BAS_ 开头的环境变量，这些变量每次执行都可能不同，并且不影响规则的解析
+var ruleCacheVolatileEnv = []string{
+	envKey("CMD"), envKey("ARGS"), envKey("EXIT_CODE"), envKey("DURATION_MS"),
+}
+
+// ruleCacheEnvKey 环境变量 key 是否用于计算缓存 key
+func ruleCacheEnvKey(key string) bool {
+	if strings.HasPrefix(key, envKeyPrefix) {
+		return !slices.Contains(ruleCacheVolatileEnv, key)
+	}
+	return slices.Contains(ruleCacheEnv, key)
+}
+
+func ruleCacheEnabled() bool {
+	// 调试模式下总是完整的解析，以输出所有的过程日志
+	return os.Getenv(envKey("NoCache")) != "true" && !enableTrace
+}
+
+// ruleCachePath 命令 name 在当前目录的规则缓存文件，key 包括当前目录和 ruleCacheEnvKey 中的环境变量
+func ruleCachePath(name string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n", ruleCacheFormat, version, name, wd)
+	env := os.Environ()
+	slices.Sort(env)
+	for _, kv := range env {
+		k, _, _ := strings.Cut(kv, "=")
+		if ruleCacheEnvKey(k) {
+			h.Write([]byte(kv + "\n"))
+		}
+	}
+	key := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(xattr.DataDir(), "rule_cache", name, key+".json"), nil
+}
+
+// loadRuleCache 读取命令 name 在当前目录的规则缓存，缓存不存在或者已失效时返回 nil
+func loadRuleCache(name string) *Rule {
+	if !ruleCacheEnabled() {
+		return nil
+	}
+	fp, err := ruleCachePath(name)
+	if err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(fp)
+	if err != nil {
+		return nil
+	}
+	rc := &ruleCache{}
+	if err = json.Unmarshal(content, rc); err != nil || rc.Format != ruleCacheFormat || rc.Rule == nil {
+		return nil
+	}
+	for _, dep := range rc.Deps {
+		if dep.changed() {
+			return nil
+		}
+	}
+	for k, v := range rc.Env {
+		if os.Getenv(k) != v {
+			return nil
+		}
+	}
+	r := rc.Rule
+	r.matched = rc.Matched
+	r.decisions = rc.Decisions
+	r.lockFile = rc.LockFile
+	if r.Trace {
+		log.Printf("Using Rule Cache %q, %s\n", fp, r.matched)
+		for _, msg := range r.decisions {
+			log.Println(msg)
+		}
+	}
+	return r
+}
+
+// recordRuleCache 开始记录解析规则所依赖的文件和目录，
+// 返回的 save 在规则解析成功后调用，将规则写入缓存
+func recordRuleCache(name string) (save func(r *Rule)) {
+	if !ruleCacheEnabled() {
+		return func(*Rule) {}
+	}
+	cacheRecorder = &depRecorder{deps: map[string]cacheDep{}, env: map[string]string{}}
+	return func(r *Rule) {
+		dr := cacheRecorder
+		cacheRecorder = nil
+		if dr.noCache != "" {
+			if fp, err := ruleCachePath(name); err == nil {
+				_ = os.Remove(fp)
+			}
+			if r.Trace {
+				log.Println("Rule Cache disabled:", dr.noCache)
+			}
+			return
+		}
+		if err := saveRuleCache(name, r, dr); err != nil && r.Trace {
+			log.Println("save Rule Cache failed:", err)
+		}
+	}
+}
+
+func saveRuleCache(name string, r *Rule, dr *depRecorder) error {
+	// 查找命令(exec.LookPath)和 PATH 中的各个版本依赖 PATH 中的目录
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			dr.deps[dir] = newCacheDep(dir)
+		}
+	}
+	// 当前程序更新后，解析的逻辑也可能变化
+	if fp, err := os.Executable(); err == nil {
+		dr.deps[fp] = newCacheDep(fp)
+	}
+
+	rc := &ruleCache{
+		Format:    ruleCacheFormat,
+		Env:       dr.env,
+		Rule:      r,
+		Matched:   r.matched,
+		Decisions: r.decisions,
+		LockFile:  r.lockFile,
+	}
+	for _, k := range slices.Sorted(maps.Keys(dr.deps)) {
+		rc.Deps = append(rc.Deps, dr.deps[k])
+	}
+	content, err := json.Marshal(rc)
+	if err != nil {
+		return err
+	}
+	fp, err := ruleCachePath(name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+	// 先写入临时文件再重命名，避免同时执行的进程读到不完整的内容
+	f, err := os.CreateTemp(filepath.Dir(fp), filepath.Base(fp)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fp)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
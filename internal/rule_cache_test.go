@@ -0,0 +1,101 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuleCache(t *testing.T) {
+	setDataDir(t, t.TempDir())
+	t.Setenv(envKey("NoCache"), "")
+	dir := t.TempDir()
+	mod := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(mod, []byte("module x\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, ".bas", "x.toml")
+
+	save := recordRuleCache("x")
+	watchPath(mod)
+	watchPath(created)
+	r := &Rule{Cmd: "/usr/bin/x", Env: []string{"K=V"}, matched: "Score=1"}
+	r.decide("using %q", r.Cmd)
+	save(r)
+	if cacheRecorder != nil {
+		t.Fatal("cacheRecorder should be reset")
+	}
+
+	got := loadRuleCache("x")
+	if got == nil {
+		t.Fatal("loadRuleCache() = nil")
+	}
+	if got.Cmd != r.Cmd || len(got.Env) != 1 || got.matched != r.matched || len(got.decisions) != 1 {
+		t.Fatalf("loadRuleCache() = %+v", got)
+	}
+	if loadRuleCache("y") != nil {
+		t.Fatal("other command should not use the cache")
+	}
+
+	// 依赖的文件被创建后失效
+	if err := os.MkdirAll(filepath.Dir(created), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(created, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if loadRuleCache("x") != nil {
+		t.Fatal("cache should be invalid after file created")
+	}
+
+	save = recordRuleCache("x")
+	disableRuleCache("condition exec x.sh")
+	save(r)
+	if loadRuleCache("x") != nil {
+		t.Fatal("cache should not be saved")
+	}
+}
+
+func TestRuleCache_env(t *testing.T) {
+	setDataDir(t, t.TempDir())
+	t.Setenv(envKey("NoCache"), "")
+	t.Setenv("BAS_TEST_UNUSED", "1")
+	t.Setenv("TEST_UNUSED", "1")
+	t.Setenv("TEST_READ", "1")
+	t.Setenv("TEST_CONFIG", "1")
+	t.Chdir(t.TempDir())
+	cf := filepath.Join(t.TempDir(), "x.toml")
+	writeFile(t, cf, "Cmd = \"{env.TEST_CONFIG|x}\"\n")
+
+	save := recordRuleCache("x")
+	getenv("TEST_READ")
+	watchConfigEnv(cf)
+	save(&Rule{Cmd: "/usr/bin/x"})
+
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		// 解析规则时没有读取的，不影响缓存
+		{key: "TEST_UNUSED", valid: true},
+		{key: envKey("ARGS"), valid: true},
+		{key: "TEST_READ"},
+		{key: "TEST_CONFIG"},
+		{key: "BAS_TEST_UNUSED"},
+		{key: "PATH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv(tt.key, "2")
+			if got := loadRuleCache("x") != nil; got != tt.valid {
+				t.Errorf("cache valid = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+	if loadRuleCache("x") == nil {
+		t.Fatal("cache should be valid after env restored")
+	}
+}
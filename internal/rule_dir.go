@@ -0,0 +1,177 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// dirRegexpPrefix Rule.Dir 中以此前缀开头的，表示使用正则表达式匹配当前目录
+const dirRegexpPrefix = "regexp:"
+
+// dirPattern Rule.Dir 中的一条规则，支持 3 种形式：
+//
+//  1. 普通目录，如 "~/workspace/myserver"：当前目录是此目录或者其子目录
+//  2. glob，如 "~/workspace/*-legacy"、"~/src/**/service"：
+//     "*" 匹配一级目录名中的任意字符，"**" 匹配任意多级目录
+//  3. 正则，如 "regexp:^/home/work/.+/service/"：使用当前目录(以路径分隔符结尾)来匹配
+type dirPattern struct {
+	raw  string
+	re   *regexp.Regexp
+	segs []string // glob 按目录分隔后的每一段
+
+	// literal 固定的字符个数，用于计算匹配的分值
+	literal int
+}
+
+func newDirPattern(dir string) (*dirPattern, error) {
+	dp := &dirPattern{raw: dir}
+	if after, ok := strings.CutPrefix(dir, dirRegexpPrefix); ok {
+		re, err := regexp.Compile(after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Dir %q: %w", dir, err)
+		}
+		dp.re = re
+		if st, err := syntax.Parse(after, syntax.Perl); err == nil {
+			dp.literal = regexpLiteralLen(st.Simplify())
+		}
+		return dp, nil
+	}
+	if isGlobPattern(dir) {
+		segs := splitPath(dir)
+		for _, seg := range segs {
+			if seg == "**" {
+				continue
+			}
+			if _, err := filepath.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("invalid Dir %q: %w", dir, err)
+			}
+		}
+		dp.segs = segs
+		dp.literal = globLiteralLen(segs)
+		return dp, nil
+	}
+	dp.literal = utf8.RuneCountInString(dir)
+	return dp, nil
+}
+
+// formatDir 格式化 Rule.Dir 中的目录，展开 "~"，并以路径分隔符结尾，正则的保持不变
+func formatDir(dir string) string {
+	if len(dir) == 0 || strings.HasPrefix(dir, dirRegexpPrefix) {
+		return dir
+	}
+	return expandHome(filepath.Clean(dir)) + string(filepath.Separator)
+}
+
+func isGlobPattern(dir string) bool {
+	return strings.ContainsAny(dir, "*?[")
+}
+
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, func(r rune) bool {
+		return r == filepath.Separator || r == '/'
+	})
+}
+
+// 分值 = 固定的字符个数 * dirScoreUnit + 类型的加分，所有匹配的分值都高于没有 Dir 的默认规则(1 分)
+// 固定的字符越多表示越精确，固定的字符个数相同时：普通目录 > glob > 正则
+const (
+	dirScoreUnit   = 5
+	dirScorePrefix = 2
+	dirScoreGlob   = 1
+)
+
+// Match 判断当前目录 wd(以路径分隔符结尾) 是否匹配，返回匹配的分值，0 为不匹配
+//
+// 固定的字符个数：普通目录为目录的长度，glob 为其中非通配的字符和路径分隔符的个数，
+// 正则为表达式中一定会匹配的字面字符的个数，详见 regexpLiteralLen
+func (dp *dirPattern) Match(wd string) int {
+	switch {
+	case dp.re != nil:
+		if !dp.re.MatchString(wd) {
+			return 0
+		}
+		return max(dp.literal*dirScoreUnit, 2)
+	case len(dp.segs) > 0:
+		if !matchGlobPrefix(dp.segs, splitPath(wd)) {
+			return 0
+		}
+		return max(dp.literal*dirScoreUnit+dirScoreGlob, 2)
+	default:
+		if strings.HasPrefix(wd, dp.raw) {
+			return dp.literal*dirScoreUnit + dirScorePrefix
+		}
+		return 0
+	}
+}
+
+// globLiteralLen glob 中非通配的字符和路径分隔符的个数，和普通目录的长度相当
+func globLiteralLen(segs []string) int {
+	n := 1 // 开头的路径分隔符
+	for _, seg := range segs {
+		if seg == "**" {
+			continue
+		}
+		for _, c := range seg {
+			if !strings.ContainsRune("*?[]", c) {
+				n++
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// regexpLiteralLen 正则中一定会匹配的字面字符的个数，如 "^/home/.+/api/" 为 "/home/" 和 "/api/" 共 11 个
+func regexpLiteralLen(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune)
+	case syntax.OpConcat, syntax.OpCapture:
+		var n int
+		for _, sub := range re.Sub {
+			n += regexpLiteralLen(sub)
+		}
+		return n
+	case syntax.OpAlternate:
+		n := -1
+		for _, sub := range re.Sub {
+			if l := regexpLiteralLen(sub); n == -1 || l < n {
+				n = l
+			}
+		}
+		return max(n, 0)
+	case syntax.OpPlus:
+		return regexpLiteralLen(re.Sub[0])
+	case syntax.OpRepeat:
+		return regexpLiteralLen(re.Sub[0]) * re.Min
+	default:
+		return 0
+	}
+}
+
+// matchGlobPrefix 判断 pattern 是否能匹配 names 的前缀部分，即当前目录是匹配目录本身或者其子目录
+func matchGlobPrefix(pattern []string, names []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(names); i++ {
+			if matchGlobPrefix(pattern[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(names) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pattern[0], names[0])
+	return ok && matchGlobPrefix(pattern[1:], names[1:])
+}
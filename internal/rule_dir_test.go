@@ -0,0 +1,104 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestRule_Match(t *testing.T) {
+	sep := string(filepath.Separator)
+	wd := filepath.Join(homeDir, "workspace", "pay-legacy", "api") + sep
+	tests := []struct {
+		name string
+		dir  []string
+		want bool
+	}{
+		{name: "no dir", dir: nil, want: true},
+		{name: "prefix", dir: []string{"~/workspace/pay-legacy"}, want: true},
+		{name: "prefix not match", dir: []string{"~/workspace/pay"}, want: false},
+		{name: "glob star", dir: []string{"~/workspace/*-legacy"}, want: true},
+		{name: "glob star not match", dir: []string{"~/workspace/*-new"}, want: false},
+		{name: "glob double star", dir: []string{"~/**/api"}, want: true},
+		{name: "glob double star zero dir", dir: []string{"~/workspace/**/pay-legacy"}, want: true},
+		{name: "glob double star not match", dir: []string{"~/**/web"}, want: false},
+		{name: "regexp", dir: []string{dirRegexpPrefix + `legacy[/\\]api[/\\]$`}, want: true},
+		{name: "regexp not match", dir: []string{dirRegexpPrefix + `^/not_exists/`}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Rule{Dir: tt.dir}
+			if err := r.Format(); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if got := r.Match(wd) > 0; got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_MatchScore(t *testing.T) {
+	wd := filepath.Join(homeDir, "src", "shop", "service") + string(filepath.Separator)
+	score := func(dir string) int {
+		r := &Rule{Dir: []string{dir}}
+		if err := r.Format(); err != nil {
+			t.Fatalf("Format(%q) error = %v", dir, err)
+		}
+		return r.Match(wd)
+	}
+	def := (&Rule{}).Match(wd)
+	glob := score("~/src/*/service")
+	prefix := score("~/src/shop")
+	exact := score("~/src/shop/service")
+	if glob <= def || prefix <= def {
+		t.Fatalf("expect score greater than default rule, glob=%d prefix=%d default=%d", glob, prefix, def)
+	}
+	if glob <= prefix {
+		t.Errorf("expect glob(%d) greater than shorter prefix(%d)", glob, prefix)
+	}
+	if exact <= glob {
+		t.Errorf("expect exact dir(%d) greater than glob(%d)", exact, glob)
+	}
+}
+
+func TestRule_MatchScoreRegexp(t *testing.T) {
+	sep := string(filepath.Separator)
+	wd := filepath.Join(homeDir, "src", "shop", "service") + sep
+	score := func(dir string) int {
+		r := &Rule{Dir: []string{dir}}
+		if err := r.Format(); err != nil {
+			t.Fatalf("Format(%q) error = %v", dir, err)
+		}
+		return r.Match(wd)
+	}
+	quote := func(p string) string {
+		return regexp.QuoteMeta(p + sep)
+	}
+	prefix := score("~/src/shop")
+	same := score(dirRegexpPrefix + "^" + quote(filepath.Join(homeDir, "src", "shop")))
+	longer := score(dirRegexpPrefix + "^" + quote(filepath.Join(homeDir, "src")) + "[a-z]+" + quote("") + "service" + quote(""))
+	loose := score(dirRegexpPrefix + ".*")
+	if same == 0 || longer == 0 || loose == 0 {
+		t.Fatalf("regexp should match, same=%d longer=%d loose=%d", same, longer, loose)
+	}
+	if prefix <= same {
+		t.Errorf("expect prefix(%d) greater than regexp with the same literal(%d)", prefix, same)
+	}
+	if longer <= prefix {
+		t.Errorf("expect regexp with more literal(%d) greater than shorter prefix(%d)", longer, prefix)
+	}
+	if def := (&Rule{}).Match(wd); loose <= def {
+		t.Errorf("expect regexp(%d) greater than default rule(%d)", loose, def)
+	}
+}
+
+func TestRule_FormatInvalidDir(t *testing.T) {
+	r := &Rule{Dir: []string{dirRegexpPrefix + "("}}
+	if err := r.Format(); err == nil {
+		t.Fatal("expect error for invalid regexp")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cmdSchema 输出配置文件的 JSON Schema，
+// name 不为空时，Spec 使用此命令的 Spec 定义，否则为所有支持的 Spec 之一
+func cmdSchema(name string) error {
+	bf, err := json.MarshalIndent(configSchema(name), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bf))
+	return nil
+}
+
+// configSchema 从 Config 等结构体的定义生成 JSON Schema
+func configSchema(name string) map[string]any {
+	sg := &schemaGen{
+		defs:     map[string]any{},
+		specName: name,
+	}
+	root := sg.typeSchema(reflect.TypeFor[Config](), "")
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = "bin-auto-switcher config"
+	if name != "" {
+		root["title"] = fmt.Sprintf("bin-auto-switcher config for %q", name)
+	}
+	root["definitions"] = sg.defs
+	return root
+}
+
+type schemaGen struct {
+	defs     map[string]any
+	specName string
+}
+
+var (
+	durationType  = reflect.TypeFor[time.Duration]()
+	conditionType = reflect.TypeFor[Condition]()
+)
+
+func (sg *schemaGen) typeSchema(t reflect.Type, field string) map[string]any {
+	switch t {
+	case durationType:
+		return map[string]any{
+			"type":    "string",
+			"pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+		}
+	case conditionType:
+		return conditionSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return sg.typeSchema(t.Elem(), field)
+	case reflect.String:
+		if field == "On" {
+			return map[string]any{"type": "string", "enum": []string{onSuccess, onFailure, onAlways}}
+		}
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": sg.typeSchema(t.Elem(), ""),
+		}
+	case reflect.Map:
+		if field == "Spec" {
+			return sg.specSchema()
+		}
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": sg.typeSchema(t.Elem(), ""),
+		}
+	case reflect.Struct:
+		return sg.structRef(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structRef 结构体放在 definitions 中，返回对其的引用，根结构体 Config 直接展开
+func (sg *schemaGen) structRef(t reflect.Type) map[string]any {
+	if t == reflect.TypeFor[Config]() {
+		return sg.structSchema(t, false)
+	}
+	name := t.Name()
+	if _, ok := sg.defs[name]; !ok {
+		sg.defs[name] = map[string]any{} // 占位，避免递归引用
+		sg.defs[name] = sg.structSchema(t, false)
+	}
+	return map[string]any{"$ref": "#/definitions/" + name}
+}
+
+// structSchema 结构体的所有导出字段
+// byJSON: 是否按照 json tag 来命名字段，Spec 是通过 json 转换的，所以需要使用 json tag
+func (sg *schemaGen) structSchema(t reflect.Type, byJSON bool) map[string]any {
+	props := map[string]any{}
+	for f := range structFields(t) {
+		name := f.Name
+		if byJSON {
+			tn, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if tn == "-" {
+				continue
+			}
+			if tn != "" {
+				name = tn
+			}
+		}
+		props[name] = sg.typeSchema(f.Type, f.Name)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// structFields 结构体的所有导出字段，包括匿名嵌入的结构体的字段
+func structFields(t reflect.Type) func(yield func(reflect.StructField) bool) {
+	return func(yield func(reflect.StructField) bool) {
+		for _, f := range reflect.VisibleFields(t) {
+			if !f.IsExported() || f.Anonymous {
+				continue
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}
+}
+
+func (sg *schemaGen) specSchema() map[string]any {
+	if sg.specName != "" {
+		return sg.structSchema(reflect.TypeOf(getSpecParser(sg.specName)).Elem(), true)
+	}
+	var items []any
+	titles := map[reflect.Type]map[string]any{}
+	for _, name := range slices.Sorted(maps.Keys(specParsers)) {
+		rt := reflect.TypeOf(specParsers[name](name)).Elem()
+		if sc, ok := titles[rt]; ok {
+			sc["title"] = sc["title"].(string) + "," + name
+			continue
+		}
+		sc := sg.structSchema(rt, true)
+		sc["title"] = name
+		titles[rt] = sc
+		items = append(items, sc)
+	}
+	// 其他命令使用通用的 Spec
+	sc := sg.structSchema(reflect.TypeOf(newSpecVersionFile("")).Elem(), true)
+	sc["title"] = "others"
+	items = append(items, sc)
+	return map[string]any{"anyOf": items}
+}
+
+func conditionSchema() map[string]any {
+	var funcs []string
+	for name := range conditionsFuncs {
+		funcs = append(funcs, regexp.QuoteMeta(name))
+	}
+	slices.Sort(funcs)
+	return map[string]any{
+		"type": "string",
+		"anyOf": []any{
+			map[string]any{"enum": slices.Sorted(maps.Keys(conditions))},
+			map[string]any{"pattern": `^\s*(` + strings.Join(funcs, "|") + `)\s+\S.*$`},
+		},
+	}
+}
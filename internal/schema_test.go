@@ -0,0 +1,63 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema(t *testing.T) {
+	bf, err := json.Marshal(configSchema("go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]any
+	if err = json.Unmarshal(bf, &root); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	// get 按照路径获取 schema 中的节点，遇到 $ref 时转到 definitions 中
+	get := func(path ...string) map[string]any {
+		node := root
+		for _, key := range path {
+			if ref, ok := node["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/definitions/")
+				node = root["definitions"].(map[string]any)[name].(map[string]any)
+			}
+			next, ok := node[key].(map[string]any)
+			if !ok {
+				t.Fatalf("%q not found in schema", path)
+			}
+			node = next
+		}
+		return node
+	}
+	tests := []struct {
+		path []string
+		want string
+	}{
+		{path: []string{"properties", "Rules"}, want: "array"},
+		{path: []string{"properties", "Rules", "items", "properties", "Pre"}, want: "array"},
+		{path: []string{"properties", "Rules", "items", "properties", "Pre", "items", "properties", "Needs"}, want: "array"},
+		{path: []string{"properties", "Rules", "items", "properties", "Pre", "items", "properties", "Needs", "items"}, want: "string"},
+		{path: []string{"properties", "Rules", "items", "properties", "Post", "items", "properties", "On"}, want: "string"},
+		{path: []string{"properties", "Rules", "items", "properties", "Alias"}, want: "object"},
+		{path: []string{"properties", "Rules", "items", "properties", "Alias", "additionalProperties"}, want: "string"},
+		{path: []string{"properties", "Rules", "items", "properties", "Pre", "items", "properties", "Timeout"}, want: "string"},
+		{path: []string{"properties", "Rules", "items", "properties", "Spec", "properties", "GoVersionFile"}, want: "string"},
+	}
+	for _, tt := range tests {
+		if got := get(tt.path...)["type"]; got != tt.want {
+			t.Errorf("%q type = %v, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	on := get("properties", "Rules", "items", "properties", "Post", "items", "properties", "On")
+	if got, want := fmt.Sprint(on["enum"]), "[success failure always]"; got != want {
+		t.Errorf("On enum = %s, want %s", got, want)
+	}
+}
@@ -30,14 +30,25 @@ SubCommands:
         list all links/configs
 
     info {name}:
-         output information about {name}
+         output information about {name}, including the rule used and the decisions made by Spec
 
     init-conf {name}:
          create global config file for {name} if not exists
 
+    validate [name]:
+         check config files (global and local) for {name}, or all if {name} is empty
+
+    schema [name]:
+         output JSON Schema of config file for {name}
+
+    lock [name]:
+         record the binary used by {name} in current dir to .bas/lock.toml,
+         or all the commands configured in .bas/*.toml if {name} is empty
+
 Env Vars:
     1. with BAS_NoHook=true to disable Pre and Post Hooks
     2. with BAS_Trace=true to enable trace logs
+    3. with BAS_NoCache=true to disable the rule cache
 
 Self-Update :
           go install github.com/fsgo/bin-auto-switcher/bas@latest
@@ -70,6 +81,12 @@ func executeSelf(ctx context.Context, args stringSlice) {
 		err = info(args.get(1))
 	case "init-conf":
 		err = initConf(args.get(1))
+	case "validate":
+		err = validateConfigs(args.get(1))
+	case "schema":
+		err = cmdSchema(args.get(1))
+	case "lock":
+		err = cmdLock(args.get(1))
 	default:
 		// eval 方式执行其他命令：
 		// bas git st
@@ -156,8 +173,20 @@ func info(cmd string) error {
 	if err != nil {
 		return err
 	}
+	// 和执行命令时一样，应用 Spec，以展示其所做的决策，但不安装缺失的版本
+	rule.dryRun = true
+	specErr := rule.BeforeExec(context.Background(), cmd)
 	bf, err := json.MarshalIndent(rule, " ", "  ")
-	fmt.Printf("Config: %s \nUsing Rule:\n%s\n", cfg.fileName, string(bf))
+	fmt.Printf("Config: %s \nMatched By: %s\nUsing Rule:\n%s\n", strings.Join(cfg.fileNames, ", "), rule.matched, string(bf))
+	if len(rule.decisions) > 0 {
+		fmt.Println("Spec Decisions:")
+		for _, d := range rule.decisions {
+			fmt.Println("  -", d)
+		}
+	}
+	if specErr != nil {
+		fmt.Println("Spec Error:", specErr)
+	}
 	return err
 }
 
@@ -0,0 +1,277 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semVersion 版本号，如 1.22.3、18.17.0、3.12.0rc1
+type semVersion struct {
+	Major int
+	Minor int
+	Patch int
+
+	// Pre 预发布版本，如 rc1、beta.2
+	Pre string
+
+	// Parts 版本号中实际给出的数字个数，如 "1.22" 为 2
+	Parts int
+}
+
+var versionReg = regexp.MustCompile(`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:[-+._]?([0-9A-Za-z][0-9A-Za-z.\-]*))?$`)
+
+// parseVersion 解析版本号，支持 "v18.17.0"、"1.22"、"1.22rc1"、"3.12.0-beta.1" 等格式
+func parseVersion(str string) (semVersion, bool) {
+	m := versionReg.FindStringSubmatch(strings.TrimSpace(str))
+	if m == nil {
+		return semVersion{}, false
+	}
+	var v semVersion
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i := 0; i < 3; i++ {
+		if m[i+1] == "" {
+			break
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return semVersion{}, false
+		}
+		*nums[i] = n
+		v.Parts++
+	}
+	v.Pre = m[4]
+	return v, true
+}
+
+func (v semVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare 比较版本号大小，有 Pre 的比没有的小
+func (v semVersion) Compare(o semVersion) int {
+	if c := cmp.Compare(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	default:
+		return strings.Compare(v.Pre, o.Pre)
+	}
+}
+
+// bump 将版本号第 n 位(从 1 开始)加一，后面的清零，如 1.22.3 bump(2) 为 1.23.0
+func (v semVersion) bump(n int) semVersion {
+	switch n {
+	case 1:
+		return semVersion{Major: v.Major + 1, Parts: 3}
+	case 2:
+		return semVersion{Major: v.Major, Minor: v.Minor + 1, Parts: 3}
+	default:
+		return semVersion{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1, Parts: 3}
+	}
+}
+
+type versionCond struct {
+	Op      string // 只会是 >=、<、= 、!= 之一
+	Version semVersion
+}
+
+func (c versionCond) check(v semVersion) bool {
+	n := v.Compare(c.Version)
+	switch c.Op {
+	case ">=":
+		return n >= 0
+	case "<":
+		return n < 0
+	case "!=":
+		return n != 0
+	default:
+		return n == 0
+	}
+}
+
+// versionConstraint 版本约束，多组之间是"或"的关系，每一组内的条件是"与"的关系
+type versionConstraint [][]versionCond
+
+// parseConstraint 解析版本约束，支持：
+//
+//	1.22、1.22.x、1.*   : 1.22.x
+//	>=1.21 <1.23        : 多个条件使用空格或者 "," 分隔
+//	~1.20、~=3.10        : ~ 为 npm 语义，~= 为 PEP 440 语义
+//	^18.2               : npm 语义
+//	1.2 - 2.3           : npm 的范围
+//	18 || 20            : 或
+//	*、""               : 任意版本
+func parseConstraint(str string) (versionConstraint, error) {
+	var result versionConstraint
+	for group := range strings.SplitSeq(str, "||") {
+		conds, err := parseConstraintGroup(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", str, err)
+		}
+		result = append(result, conds)
+	}
+	return result, nil
+}
+
+var constraintOpReg = regexp.MustCompile(`(>=|<=|==|!=|~=|>|<|=|~|\^)\s+`)
+
+func parseConstraintGroup(str string) ([]versionCond, error) {
+	// 运算符和版本号之间的空格去掉，如 ">= 1.2" -> ">=1.2"
+	str = constraintOpReg.ReplaceAllString(strings.TrimSpace(str), "$1")
+	fields := strings.FieldsFunc(str, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	var result []versionCond
+	for i := 0; i < len(fields); i++ {
+		// npm 的范围 "1.2 - 2.3"
+		if i+2 < len(fields) && fields[i+1] == "-" {
+			lo, err := parseRangeItem(">=" + fields[i])
+			if err != nil {
+				return nil, err
+			}
+			hi, err := parseRangeItem("<=" + fields[i+2])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, lo...)
+			result = append(result, hi...)
+			i += 2
+			continue
+		}
+		conds, err := parseRangeItem(fields[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, conds...)
+	}
+	return result, nil
+}
+
+func parseRangeItem(item string) ([]versionCond, error) {
+	var op string
+	for _, o := range []string{">=", "<=", "==", "!=", "~=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(item, o) {
+			op = o
+			break
+		}
+	}
+	str := strings.TrimSpace(item[len(op):])
+
+	// 通配符：1.x、1.2.*、*
+	var wildcard bool
+	if idx := strings.IndexAny(str, "xX*"); idx != -1 {
+		wildcard = true
+		str = strings.TrimRight(str[:idx], ".")
+	}
+	if str == "" {
+		if wildcard || op == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("missing version in %q", item)
+	}
+	v, ok := parseVersion(str)
+	if !ok {
+		return nil, fmt.Errorf("invalid version %q", str)
+	}
+	partial := wildcard || (v.Parts < 3 && v.Pre == "")
+
+	switch op {
+	case "", "=", "==":
+		if !partial {
+			return []versionCond{{Op: "=", Version: v}}, nil
+		}
+		return []versionCond{{Op: ">=", Version: v}, {Op: "<", Version: v.bump(v.Parts)}}, nil
+	case "!=":
+		// != 1.2.* 暂不支持，当作不等于 1.2.0
+		return []versionCond{{Op: "!=", Version: v}}, nil
+	case ">=":
+		return []versionCond{{Op: ">=", Version: v}}, nil
+	case ">":
+		if partial {
+			return []versionCond{{Op: ">=", Version: v.bump(v.Parts)}}, nil
+		}
+		return []versionCond{{Op: ">=", Version: v}, {Op: "!=", Version: v}}, nil
+	case "<":
+		return []versionCond{{Op: "<", Version: v}}, nil
+	case "<=":
+		if partial {
+			return []versionCond{{Op: "<", Version: v.bump(v.Parts)}}, nil
+		}
+		return []versionCond{{Op: "<", Version: v.bump(3)}}, nil
+	case "~":
+		// ~1.2.3 := >=1.2.3 <1.3.0，~1 := >=1.0.0 <2.0.0
+		return []versionCond{{Op: ">=", Version: v}, {Op: "<", Version: v.bump(min(v.Parts, 2))}}, nil
+	case "~=":
+		// PEP 440: ~=3.10 := >=3.10 <4.0，~=3.10.2 := >=3.10.2 <3.11
+		if v.Parts < 2 {
+			return nil, fmt.Errorf("invalid %q, ~= requires at least 2 parts", item)
+		}
+		return []versionCond{{Op: ">=", Version: v}, {Op: "<", Version: v.bump(v.Parts - 1)}}, nil
+	case "^":
+		// ^1.2.3 := <2.0.0，^0.2.3 := <0.3.0，^0.0.3 := <0.0.4
+		n := 1
+		if v.Major == 0 && v.Parts > 1 {
+			n = 2
+			if v.Minor == 0 && v.Parts > 2 {
+				n = 3
+			}
+		}
+		return []versionCond{{Op: ">=", Version: v}, {Op: "<", Version: v.bump(n)}}, nil
+	}
+	return nil, fmt.Errorf("invalid %q", item)
+}
+
+// Check 判断版本是否满足约束
+// 预发布版本(如 1.22rc1)只有在约束中明确指定时(如 =1.22rc1)才满足
+func (vc versionConstraint) Check(v semVersion) bool {
+	if len(vc) == 0 {
+		return v.Pre == ""
+	}
+	for _, group := range vc {
+		if v.Pre != "" && !hasExactPre(group, v) {
+			continue
+		}
+		ok := true
+		for _, c := range group {
+			if !c.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasExactPre(group []versionCond, v semVersion) bool {
+	for _, c := range group {
+		if c.Op == "=" && c.Version.Pre != "" && c.Version.Compare(v) == 0 {
+			return true
+		}
+	}
+	return false
+}
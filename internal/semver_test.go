@@ -0,0 +1,68 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestVersionConstraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{constraint: "1.22", version: "1.22.5", want: true},
+		{constraint: "1.22", version: "1.23.0", want: false},
+		{constraint: "1.22.x", version: "1.22.0", want: true},
+		{constraint: "1.*", version: "1.9.0", want: true},
+		{constraint: "*", version: "21.0.1", want: true},
+		{constraint: "", version: "1.0.0", want: true},
+		{constraint: ">=1.21 <1.23", version: "1.22.3", want: true},
+		{constraint: ">=1.21 <1.23", version: "1.23.0", want: false},
+		{constraint: ">= 1.21, < 1.23", version: "1.21.0", want: true},
+		{constraint: ">1.21", version: "1.21.9", want: false},
+		{constraint: "<=1.21", version: "1.21.9", want: true},
+		{constraint: "~1.20", version: "1.20.9", want: true},
+		{constraint: "~1.20", version: "1.21.0", want: false},
+		{constraint: "~=3.10", version: "3.12.1", want: true},
+		{constraint: "~=3.10", version: "4.0.0", want: false},
+		{constraint: "~=3.10.2", version: "3.11.0", want: false},
+		{constraint: "^18.2", version: "18.19.0", want: true},
+		{constraint: "^18.2", version: "19.0.0", want: false},
+		{constraint: "^0.2.3", version: "0.3.0", want: false},
+		{constraint: "16 || 18", version: "18.1.0", want: true},
+		{constraint: "16 || 18", version: "20.1.0", want: false},
+		{constraint: "1.2 - 2.3", version: "2.3.9", want: true},
+		{constraint: "1.2 - 2.3", version: "2.4.0", want: false},
+		{constraint: "==3.11.*", version: "3.11.4", want: true},
+		{constraint: ">=3.9,!=3.10.0", version: "3.10.0", want: false},
+		{constraint: "v18.17.0", version: "18.17.0", want: true},
+		{constraint: "1.22", version: "1.22rc1", want: false},
+		{constraint: "1.22rc1", version: "1.22rc1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+"/"+tt.version, func(t *testing.T) {
+			c, err := parseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("parseConstraint(%q) error = %v", tt.constraint, err)
+			}
+			v, ok := parseVersion(tt.version)
+			if !ok {
+				t.Fatalf("parseVersion(%q) failed", tt.version)
+			}
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, str := range []string{">=", "abc", "~=3"} {
+		if _, err := parseConstraint(str); err == nil {
+			t.Errorf("parseConstraint(%q) expect error", str)
+		}
+	}
+}
@@ -5,53 +5,277 @@
 package internal
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"golang.org/x/mod/modfile"
 )
 
+type specParser interface {
+	// Parser 使用 Rule.Spec 的配置，修改 Rule 的 Cmd、Env 等
+	Parser(r *Rule) error
+}
+
+// specChecker 可选接口，用于检查 Spec 的配置是否正确
+type specChecker interface {
+	Check() error
+}
+
+// specCommon 所有 Spec 都支持的配置
+type specCommon struct {
+	// Strict 找不到要求的版本时，返回错误，而不是继续使用默认的命令，
+	// 和 Config.Strict、Rule.Strict 任意一个为 true 即生效
+	Strict bool `json:",omitempty"`
+
+	// ToolVersions 是否使用 asdf、mise 的 .tool-versions 文件中定义的版本，支持：
+	// auto: 在当前目录以及上级目录查找 .tool-versions，使用其中当前命令对应的版本，
+	//       先于各命令自身的版本文件(如 GoVersionFile)执行，找到了版本时，不再使用命令自身的版本文件，
+	//       其他的配置(如 GoWork、Venv)仍然生效
+	// "", no: 跳过
+	ToolVersions string `json:",omitempty"`
+
+	// ToolName .tool-versions 中的工具名称，可选，默认根据命令推断，如 node、npm 为 nodejs 或 node
+	ToolName string `json:",omitempty"`
+
+	// ToolRoots 工具的安装目录，可选，目录下为 {tool}/{version}，
+	// 默认为 asdf 和 mise 的安装目录：~/.asdf/installs、~/.local/share/mise/installs
+	ToolRoots []string `json:",omitempty"`
+}
+
+func (s *specCommon) common() *specCommon {
+	return s
+}
+
+func (s *specCommon) checkCommon() error {
+	if !slices.Contains([]string{"", "no", "auto"}, s.ToolVersions) {
+		return fmt.Errorf("not support ToolVersions=%q", s.ToolVersions)
+	}
+	return nil
+}
+
+// specMissError 找不到 Spec 要求的版本时的错误
+type specMissError struct {
+	Bin      string   // 命令名称，如 go
+	Want     string   // 要求的版本，如 go1.21.5
+	From     string   // 定义版本的文件
+	Searched []string // 查找过的位置
+	Hint     string   // 提示如何解决
+}
+
+func (e *specMissError) Error() string {
+	msg := fmt.Sprintf("%s %q required by %q not found, searched: %q", e.Bin, e.Want, e.From, e.Searched)
+	if e.Hint != "" {
+		msg += ", hint: " + e.Hint
+	}
+	return msg
+}
+
+// missing 找不到 Spec 要求的版本：Strict 模式下返回错误，否则打印日志后忽略，继续使用默认的命令
+func (s *specCommon) missing(r *Rule, e *specMissError) error {
+	if s.Strict || r.Strict {
+		return e
+	}
+	r.decide("%s, ignore it", e.Error())
+	return nil
+}
+
+// specParsers 所有支持 Spec 的命令，参数 name 为命令名称，如 go、npm
+var specParsers = map[string]func(name string) specParser{
+	"go": func(string) specParser {
+		return &specGo{}
+	},
+	"node": newSpecNode,
+	"npm":  newSpecNode,
+	"npx":  newSpecNode,
+
+	"python":  newSpecPython,
+	"python3": newSpecPython,
+	"pip":     newSpecPython,
+	"pip3":    newSpecPython,
+	"pytest":  newSpecPython,
+
+	"java":    newSpecJava,
+	"javac":   newSpecJava,
+	"jar":     newSpecJava,
+	"jshell":  newSpecJava,
+	"javadoc": newSpecJava,
+	"mvn":     newSpecJava,
+	"gradle":  newSpecJava,
+
+	"cargo":        newSpecRust,
+	"rustc":        newSpecRust,
+	"rustdoc":      newSpecRust,
+	"rustfmt":      newSpecRust,
+	"cargo-clippy": newSpecRust,
+}
+
+// getSpecParser 获取命令 name 的 Spec，没有专门的 Spec 的命令，使用通用的 specVersionFile
+func getSpecParser(name string) specParser {
+	if fn, ok := specParsers[name]; ok {
+		return fn(name)
+	}
+	return newSpecVersionFile(name)
+}
+
 func parserSpecial(name string, r *Rule) error {
-	if len(r.Spec) == 0 {
+	if len(r.Spec) == 0 && r.Version == "" {
 		return nil
 	}
-	switch name {
-	case "go":
-		s := &specGo{}
-		return s.Parser(r)
+	// 所有 Spec 都支持的 .tool-versions，先于各 Spec 自身的逻辑执行，
+	// 其找到了版本时，各 Spec 不再使用自身的版本文件，以免后者覆盖前者，但仍然设置环境变量等
+	var sc specCommon
+	if err := convertByJSON(r.Spec, &sc); err != nil {
+		return err
+	}
+	if err := sc.checkCommon(); err != nil {
+		return err
+	}
+	if err := sc.toolVersions(r, name); err != nil {
+		return err
+	}
+	if r.toolPick != nil {
+		r.decide("version defined in %q wins, skip the version files of the %s spec", r.toolPick.File, name)
+	}
+	return getSpecParser(name).Parser(r)
+}
+
+// commonSpec 包含 specCommon 的 Spec
+type commonSpec interface {
+	common() *specCommon
+}
+
+// checkSpec 检查命令 name 的 Spec 配置，包括是否有不支持的字段
+func checkSpec(name string, spec map[string]any) error {
+	if len(spec) == 0 {
+		return nil
+	}
+	sp := getSpecParser(name)
+	bf, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bf))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(sp); err != nil {
+		return err
+	}
+	if cs, ok := sp.(commonSpec); ok {
+		if err = cs.common().checkCommon(); err != nil {
+			return err
+		}
+	}
+	if sc, ok := sp.(specChecker); ok {
+		return sc.Check()
 	}
 	return nil
 }
 
 type specGo struct {
+	specCommon
+
 	// GoVersionFile  定义 go 版本的文件，目前支持 go.mod、no
+	// 使用 go.mod 时，优先使用 toolchain 指令的版本，如 go1.22.3，
+	// 否则使用满足 go 指令的、已安装的同一个次版本中最新的，如 "go 1.22" 可以使用 go1.22.5
+	// 环境变量 GOTOOLCHAIN=local 时不切换，为 goX.Y.Z 时使用此版本
 	GoVersionFile string
 
 	// GoWork 是否修订当前目录未在 go.work 中定义不能运行的问题
 	// 目前支持:
-	// 1 auto: 若模块不在 go.work，则设置环境变量 GOWORK=off
+	// 1 auto: 若模块不在 go.work，则设置环境变量 GOWORK=off，否则设置 GOWORK 为 go.work 文件的路径，
+	//         已设置环境变量 GOWORK 时跳过
 	// 2 no: 跳过
 	GoWork string
+
+	// GoFlags 是否根据 vendor 目录设置环境变量 GOFLAGS 中的 -mod，目前支持：
+	// auto: 存在 vendor/modules.txt 时为 -mod=vendor，否则为 -mod=mod(workspace 模式下不设置)，
+	//       GOFLAGS 中已有 -mod 时跳过
+	// "", no: 跳过
+	GoFlags string
+
+	// GoPrivate 私有模块的路径前缀，可选，添加到环境变量 GOPRIVATE 和 GONOSUMDB 中
+	// 如 ["github.com/myorg", "git.example.com"]
+	GoPrivate []string
+
+	// GoInstall 找不到 go.mod 要求的 go 版本时，是否自动安装，支持：
+	// auto: 自动安装，安装失败时返回错误
+	// "", no: 跳过，继续使用默认的 go
+	GoInstall string
+
+	// GoInstallCmd 安装 go 的命令，可选，{version} 会被替换为版本号，如 1.21.5
+	// 默认为 "go install golang.org/dl/go{version}@latest && go{version} download"
+	GoInstallCmd string
+
+	// GoInstallDir 存放 go 安装包的目录，可选，如本地的镜像目录，
+	// 安装包的文件名和官方的一致，如 go1.21.5.linux-amd64.tar.gz，设置后不再使用 GoInstallCmd
+	GoInstallDir string
+
+	// GoSDKDir 已安装的 go 的目录，可选，默认为 ~/sdk，go{version}/bin/go 为 go 命令
+	GoSDKDir string
 }
 
 func (s *specGo) Parser(r *Rule) error {
 	if err := convertByJSON(r.Spec, s); err != nil {
 		return err
 	}
-
-	if err := s.goVersionFile(r); err != nil {
+	if err := s.Check(); err != nil {
 		return err
 	}
 
+	switch {
+	case r.toolPick != nil:
+		// 已由 .tool-versions 选择了版本，避免 go 命令再根据 go.mod 切换
+		if r.toolPick.Bin != "" {
+			s.pinToolchain(r, lookupEnv(r.Env, "GOTOOLCHAIN"))
+		}
+	case r.Version != "":
+		if err := s.pinVersion(r); err != nil {
+			return err
+		}
+	default:
+		if err := s.goVersionFile(r); err != nil {
+			return err
+		}
+	}
+
 	if err := s.goWork(r); err != nil {
 		return err
 	}
 
+	if err := s.goFlags(r); err != nil {
+		return err
+	}
+
+	s.goPrivate(r)
+
+	return nil
+}
+
+func (s *specGo) Check() error {
+	if !slices.Contains([]string{"", "no", "go.mod"}, s.GoVersionFile) {
+		return fmt.Errorf("not support GoVersionFile=%q, now support 'go.mod'", s.GoVersionFile)
+	}
+	if !slices.Contains([]string{"", "no", "auto"}, s.GoWork) {
+		return fmt.Errorf("not support GoWork=%q", s.GoWork)
+	}
+	if !slices.Contains([]string{"", "no", "auto"}, s.GoInstall) {
+		return fmt.Errorf("not support GoInstall=%q", s.GoInstall)
+	}
+	if !slices.Contains([]string{"", "no", "auto"}, s.GoFlags) {
+		return fmt.Errorf("not support GoFlags=%q", s.GoFlags)
+	}
+	for _, p := range s.GoPrivate {
+		if p == "" || strings.ContainsAny(p, ", ") {
+			return fmt.Errorf("invalid GoPrivate %q", p)
+		}
+	}
 	return nil
 }
 
@@ -73,19 +297,193 @@ func (s *specGo) goVersionFile(r *Rule) error {
 	if err != nil {
 		return err
 	}
-	if f.Go != nil && f.Go.Version != "" {
-		cmd := "go" + f.Go.Version
-		filePath, err := exec.LookPath(cmd)
+
+	// 和 go 命令自身的 GOTOOLCHAIN 规则保持一致，详见 https://go.dev/doc/toolchain
+	gt := lookupEnv(r.Env, "GOTOOLCHAIN")
+	name, _, _ := strings.Cut(gt, "+")
+	switch {
+	case name == "local":
+		r.decide("GOTOOLCHAIN=local, skip switch go version")
+		return nil
+	case strings.HasPrefix(name, "go"):
+		// 指定了具体的版本，如 GOTOOLCHAIN=go1.21.3
+		if ok, err := s.switchGo(r, name); ok || err != nil {
+			return err
+		}
+		if err = s.missing(r, s.missError(name, "GOTOOLCHAIN")); err != nil {
+			return err
+		}
+	}
+
+	// 优先使用 toolchain 指令，如 "toolchain go1.22.3"，"toolchain default" 和没有 toolchain 指令相同
+	if f.Toolchain != nil && f.Toolchain.Name == "default" {
+		r.decide("'toolchain default' in %q, use the go directive", fp)
+	} else if f.Toolchain != nil && f.Toolchain.Name != "" {
+		ok, err := s.switchGo(r, f.Toolchain.Name)
 		if err != nil {
-			if r.Trace {
-				log.Printf("LookPath %q with error, ignore it", cmd)
-			}
-			// 当不存在的时候，忽略错误
+			return err
+		}
+		if ok {
+			s.pinToolchain(r, gt)
 			return nil
 		}
-		r.Cmd = filePath
+		if err = s.missing(r, s.missError(f.Toolchain.Name, fp)); err != nil {
+			return err
+		}
+	}
+
+	if f.Go == nil || f.Go.Version == "" {
+		return nil
+	}
+	// go 指令为最低版本要求，使用已安装的同一个次版本中最新的，如 "go 1.22" 可以使用 go1.22.5
+	if b, ok := s.findGoPatch(r, f.Go.Version); ok {
+		r.Cmd = b.Path
+		r.decide("using go%s %q for 'go %s' in %q", b.Version, b.Path, f.Go.Version, fp)
+		s.pinToolchain(r, gt)
+		return nil
+	}
+	if s.useGoCmd(r, "go"+f.Go.Version) {
+		s.pinToolchain(r, gt)
+		return nil
+	}
+	ok, err := s.tryInstall(r, goReleaseName(f.Go.Version))
+	if err != nil {
+		return err
+	}
+	if ok {
+		s.pinToolchain(r, gt)
+		return nil
+	}
+	return s.missing(r, s.missError("go"+f.Go.Version, fp))
+}
+
+// pinVersion 使用 Rule.Version 指定的版本，而不是 go.mod 中的，
+// 如 "1.20" 使用已安装的最新的 go1.20.x，也支持版本范围，如 ">=1.21 <1.23"
+func (s *specGo) pinVersion(r *Rule) error {
+	want := strings.TrimPrefix(r.Version, "go")
+	vc, err := parseConstraint(want)
+	if err != nil {
+		return fmt.Errorf("invalid Version %q: %w", r.Version, err)
+	}
+	gt := lookupEnv(r.Env, "GOTOOLCHAIN")
+	if b, ok := s.resolver().Resolve(vc, r.Trace); ok {
+		r.Cmd = b.Path
+		r.decide("using go%s %q for Version=%q", b.Version, b.Path, r.Version)
+		s.pinToolchain(r, gt)
+		return nil
+	}
+	// 只能安装具体的版本
+	if _, ok := parseVersion(want); ok {
+		ok, err = s.tryInstall(r, goReleaseName(want))
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.pinToolchain(r, gt)
+			return nil
+		}
+	}
+	return s.missing(r, s.missError("go"+want, "Rule.Version"))
+}
+
+// switchGo 使用名为 name 的 go 版本(如 go1.22.3)，找不到时，若 GoInstall=auto 则安装
+func (s *specGo) switchGo(r *Rule, name string) (bool, error) {
+	if s.useGoCmd(r, name) {
+		return true, nil
+	}
+	return s.tryInstall(r, name)
+}
+
+// missError 找不到 name(如 go1.22.3) 时的错误，from 为定义版本的地方
+func (s *specGo) missError(name string, from string) *specMissError {
+	release := goReleaseName(strings.TrimPrefix(name, "go"))
+	return &specMissError{
+		Bin:      "go",
+		Want:     name,
+		From:     from,
+		Searched: []string{"$PATH", s.sdkDir()},
+		Hint:     fmt.Sprintf("install it by 'go install golang.org/dl/%s@latest && %s download', or set GoInstall=\"auto\"", release, release),
+	}
+}
+
+// useGoCmd 若能在 PATH 或者 GoSDKDir 中找到名为 name 的命令(如 go1.22.3)，则使用它
+func (s *specGo) useGoCmd(r *Rule, name string) bool {
+	filePath := s.findGoSDK(name)
+	if filePath == "" {
+		if r.Trace {
+			log.Printf("%q not found in PATH and %q\n", name, s.sdkDir())
+		}
+		return false
+	}
+	r.decide("using %s %q", name, filePath)
+	r.Cmd = filePath
+	return true
+}
+
+// tryInstall 当 GoInstall=auto 时，安装并使用名为 name 的 go 版本(如 go1.22.3)
+func (s *specGo) tryInstall(r *Rule, name string) (bool, error) {
+	if s.GoInstall != "auto" {
+		return false, nil
+	}
+	if v, ok := parseVersion(strings.TrimPrefix(name, "go")); !ok || !strings.HasPrefix(name, "go") || v.Parts < 2 {
+		return false, fmt.Errorf("cannot install go, invalid version %q", name)
+	}
+	if r.dryRun {
+		r.decide("GoInstall=auto would install %s, skipped in dry run", name)
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), goInstallTimeout)
+	defer cancel()
+	filePath, err := s.installGo(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("install %s failed: %w", name, err)
+	}
+	r.decide("installed %s, using %q", name, filePath)
+	r.Cmd = filePath
+	return true, nil
+}
+
+// goReleaseName go.mod 中 go 指令的版本对应的发布版本名称，
+// 从 go1.21 开始，首个发布版本为 go1.21.0，如 "go 1.22" 对应 go1.22.0
+func goReleaseName(version string) string {
+	v, ok := parseVersion(version)
+	if ok && v.Pre == "" && v.Parts == 2 && v.Compare(semVersion{Major: 1, Minor: 21}) >= 0 {
+		return "go" + version + ".0"
+	}
+	return "go" + version
+}
+
+// pinToolchain 已选择了满足 go.mod 要求的版本，设置 GOTOOLCHAIN=local，避免 go 命令再次切换版本
+// 只在未设置 GOTOOLCHAIN 或者其值为 auto、path 时设置
+func (s *specGo) pinToolchain(r *Rule, gt string) {
+	switch gt {
+	case "", "auto", "path", "local+auto", "local+path":
+		r.Env = append(r.Env, "GOTOOLCHAIN=local")
+		r.decide("set GOTOOLCHAIN=local, avoid go switching version again")
+	}
+}
+
+// findGoPatch 在 GoSDKDir 和 PATH 中查找满足 go.mod 中 go 指令的 go1.x.y 命令，使用同一个次版本中最新的
+func (s *specGo) findGoPatch(r *Rule, goVersion string) (versionedBin, bool) {
+	v, ok := parseVersion(goVersion)
+	if !ok || v.Pre != "" {
+		return versionedBin{}, false
+	}
+	vc := versionConstraint{{
+		{Op: ">=", Version: v},
+		{Op: "<", Version: v.bump(2)},
+	}}
+	return s.resolver().Resolve(vc, r.Trace)
+}
+
+// resolver 在 GoSDKDir 中查找 go{version}/bin/go，在 PATH 中查找 go{version}
+func (s *specGo) resolver() *versionResolver {
+	return &versionResolver{
+		InstallDirs: []string{s.sdkDir()},
+		DirPrefix:   "go",
+		Rels:        []string{filepath.Join("bin", "go")},
+		PathPrefix:  "go",
 	}
-	return nil
 }
 
 func (s *specGo) goWork(r *Rule) error {
@@ -96,6 +494,11 @@ func (s *specGo) goWork(r *Rule) error {
 		return fmt.Errorf("not support GoWork=%q", s.GoWork)
 	}
 
+	if gw := lookupEnv(r.Env, "GOWORK"); gw != "" {
+		r.decide("GOWORK=%s already set, skip GoWork", gw)
+		return nil
+	}
+
 	fp, err := findFileUpper("go.work", 128)
 	if err != nil {
 		if errors.Is(err, errFileNotFound) {
@@ -123,12 +526,12 @@ func (s *specGo) goWork(r *Rule) error {
 	for _, m := range wf.Use {
 		fullPath := filepath.Join(wfDir, m.Path) + string(filepath.Separator)
 		if strings.HasPrefix(wd, fullPath) {
+			r.Env = append(r.Env, "GOWORK="+fp)
+			r.decide("module %q in go.work, set GOWORK=%s", m.Path, fp)
 			return nil
 		}
 	}
 	r.Env = append([]string{"GOWORK=off"}, r.Env...)
-	if r.Trace {
-		log.Println("module not in go.work, set GOWORK=off")
-	}
+	r.decide("module not in %q, set GOWORK=off", fp)
 	return nil
 }
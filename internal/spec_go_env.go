@@ -0,0 +1,134 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// goFlags 根据是否有 vendor 目录，在 GOFLAGS 中添加 -mod=vendor 或者 -mod=mod
+func (s *specGo) goFlags(r *Rule) error {
+	if s.GoFlags == "" || s.GoFlags == "no" {
+		return nil
+	}
+	flags := goEnv(r.Env, "GOFLAGS")
+	if slices.ContainsFunc(strings.Fields(flags), func(f string) bool {
+		return strings.HasPrefix(f, "-mod=") || strings.HasPrefix(f, "--mod=")
+	}) {
+		r.decide("GOFLAGS=%q already has -mod, skip GoFlags", flags)
+		return nil
+	}
+
+	// workspace 模式下，vendor 目录在 go.work 所在目录，且 -mod 只能为 readonly 或者 vendor
+	root, workspace, err := goVendorRoot(r)
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	var mod string
+	modulesTxt := filepath.Join(root, "vendor", "modules.txt")
+	watchPath(modulesTxt)
+	if _, err = os.Stat(modulesTxt); err == nil {
+		mod = "-mod=vendor"
+		r.decide("found %q, set GOFLAGS %s", modulesTxt, mod)
+	} else if !workspace {
+		mod = "-mod=mod"
+		r.decide("%q not found, set GOFLAGS %s", modulesTxt, mod)
+	} else {
+		r.decide("%q not found in workspace mode, skip GoFlags", modulesTxt)
+		return nil
+	}
+	r.Env = append(r.Env, "GOFLAGS="+strings.TrimSpace(flags+" "+mod))
+	return nil
+}
+
+// goVendorRoot 返回 vendor 目录所在的目录：workspace 模式下为 go.work 所在目录，否则为 go.mod 所在目录
+func goVendorRoot(r *Rule) (dir string, workspace bool, err error) {
+	gw := lookupEnv(r.Env, "GOWORK")
+	switch {
+	case gw == "off":
+	case gw != "":
+		return filepath.Dir(gw), true, nil
+	default:
+		if fp, err := findFileUpper("go.work", 128); err == nil {
+			return filepath.Dir(fp), true, nil
+		} else if !errors.Is(err, errFileNotFound) {
+			return "", false, err
+		}
+	}
+	fp, err := findFileUpper("go.mod", 128)
+	if err != nil {
+		return "", false, err
+	}
+	return filepath.Dir(fp), false, nil
+}
+
+// goPrivate 将 GoPrivate 中的模块路径前缀添加到 GOPRIVATE 和 GONOSUMDB 中
+func (s *specGo) goPrivate(r *Rule) {
+	if len(s.GoPrivate) == 0 {
+		return
+	}
+	private := goEnv(r.Env, "GOPRIVATE")
+	for _, key := range []string{"GOPRIVATE", "GONOSUMDB"} {
+		old := goEnv(r.Env, key)
+		if key == "GONOSUMDB" && old == "" {
+			// GONOSUMDB 未设置时，go 命令使用 GOPRIVATE 的值
+			old = private
+		}
+		var list []string
+		if old != "" {
+			list = strings.Split(old, ",")
+		}
+		var added []string
+		for _, p := range s.GoPrivate {
+			if !slices.Contains(list, p) {
+				list = append(list, p)
+				added = append(added, p)
+			}
+		}
+		if len(added) == 0 {
+			r.decide("%s=%q already has %q", key, old, s.GoPrivate)
+			continue
+		}
+		value := strings.Join(list, ",")
+		r.Env = append(r.Env, key+"="+value)
+		r.decide("add %q to %s, set %s=%s", added, key, key, value)
+	}
+}
+
+// goEnv 读取 go 的环境变量，优先使用环境变量中的，否则读取 go env -w 写入的配置文件
+func goEnv(env []string, key string) string {
+	if v := lookupEnv(env, key); v != "" {
+		return v
+	}
+	fp := lookupEnv(env, "GOENV")
+	if fp == "off" {
+		return ""
+	}
+	if fp == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		fp = filepath.Join(dir, "go", "env")
+	}
+	watchPath(fp)
+	content, err := os.ReadFile(fp)
+	if err != nil {
+		return ""
+	}
+	for line := range strings.Lines(string(content)) {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
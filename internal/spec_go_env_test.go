@@ -0,0 +1,107 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpecGo_goPrivate(t *testing.T) {
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GONOSUMDB", "")
+	s := &specGo{GoPrivate: []string{"github.com/myorg", "git.example.com"}}
+
+	r := &Rule{Env: []string{"GOENV=off", "GOPRIVATE=x.com,git.example.com"}}
+	s.goPrivate(r)
+	if got := lookupEnv(r.Env, "GOPRIVATE"); got != "x.com,git.example.com,github.com/myorg" {
+		t.Fatalf("GOPRIVATE = %q", got)
+	}
+	// 未设置 GONOSUMDB 时，基于 GOPRIVATE 的值
+	if got := lookupEnv(r.Env, "GONOSUMDB"); got != "x.com,git.example.com,github.com/myorg" {
+		t.Fatalf("GONOSUMDB = %q", got)
+	}
+
+	r = &Rule{Env: []string{"GOENV=off", "GOPRIVATE=github.com/myorg,git.example.com", "GONOSUMDB=y.com"}}
+	s.goPrivate(r)
+	if len(r.Env) != 4 {
+		t.Fatalf("GOPRIVATE should not be changed, got %q", r.Env)
+	}
+	if got := lookupEnv(r.Env, "GONOSUMDB"); got != "y.com,github.com/myorg,git.example.com" {
+		t.Fatalf("GONOSUMDB = %q", got)
+	}
+	if len(r.decisions) != 2 {
+		t.Fatalf("decisions = %q", r.decisions)
+	}
+}
+
+func TestSpecGo_goFlags(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "")
+	tests := []struct {
+		name  string
+		files []string
+		env   []string
+		want  string // 期望的 GOFLAGS
+	}{
+		{
+			name:  "vendor",
+			files: []string{"go.mod", "vendor/modules.txt"},
+			want:  "-mod=vendor",
+		},
+		{
+			name:  "no vendor",
+			files: []string{"go.mod"},
+			want:  "-mod=mod",
+		},
+		{
+			name:  "keep other flags",
+			files: []string{"go.mod", "vendor/modules.txt"},
+			env:   []string{"GOFLAGS=-trimpath"},
+			want:  "-trimpath -mod=vendor",
+		},
+		{
+			name:  "already has -mod",
+			files: []string{"go.mod", "vendor/modules.txt"},
+			env:   []string{"GOFLAGS=-mod=readonly"},
+			want:  "-mod=readonly",
+		},
+		{
+			name:  "workspace without vendor",
+			files: []string{"go.mod", "../go.work"},
+			want:  "",
+		},
+		{
+			name:  "workspace off",
+			files: []string{"go.mod", "../go.work"},
+			env:   []string{"GOWORK=off"},
+			want:  "-mod=mod",
+		},
+		{
+			name: "no go.mod",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "app")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.files {
+				writeFile(t, filepath.Join(dir, name), "")
+			}
+			t.Chdir(dir)
+			r := &Rule{Env: append([]string{"GOENV=off"}, tt.env...)}
+			s := &specGo{GoFlags: "auto"}
+			if err := s.goFlags(r); err != nil {
+				t.Fatal(err)
+			}
+			if got := lookupEnv(r.Env, "GOFLAGS"); got != tt.want {
+				t.Errorf("GOFLAGS = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/xattr"
+)
+
+// goInstallTimeout 安装 go 的超时时间，包括等待其他进程安装的时间
+const goInstallTimeout = 10 * time.Minute
+
+// defaultGoInstallCmd 默认的安装 go 的命令，{version} 会被替换为版本号，如 1.21.5
+const defaultGoInstallCmd = "go install golang.org/dl/go{version}@latest && go{version} download"
+
+// installGo 安装指定版本的 go，返回安装后的 go 命令的路径
+// name: 如 go1.21.5
+func (s *specGo) installGo(ctx context.Context, name string) (string, error) {
+	version := strings.TrimPrefix(name, "go")
+	unlock, err := lockFile(ctx, filepath.Join(xattr.DataDir(), "go_install", name+".lock"))
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// 获取锁后再检查一次，可能已经被其他进程安装了
+	if fp := s.findGoSDK(name); fp != "" {
+		return fp, nil
+	}
+
+	if s.GoInstallDir != "" {
+		err = s.installFromDir(version)
+	} else {
+		err = s.installByCmd(ctx, version)
+	}
+	if err != nil {
+		return "", err
+	}
+	if fp := s.findGoSDK(name); fp != "" {
+		return fp, nil
+	}
+	return "", fmt.Errorf("%s installed, but cannot found it", name)
+}
+
+// findGoSDK 查找已安装的 go 命令，先查找 PATH 中的 {name}，然后是 {GoSDKDir}/{name}/bin/go
+func (s *specGo) findGoSDK(name string) string {
+	if fp, err := exec.LookPath(name); err == nil {
+		return fp
+	}
+	if fp := filepath.Join(s.sdkDir(), name, "bin", "go"); isExecutable(fp) {
+		return fp
+	}
+	return ""
+}
+
+// sdkDir 安装 go 的目录，和 golang.org/dl 的一致，默认为 ~/sdk
+func (s *specGo) sdkDir() string {
+	if s.GoSDKDir != "" {
+		return expandHome(s.GoSDKDir)
+	}
+	return filepath.Join(homeDir, "sdk")
+}
+
+func (s *specGo) installByCmd(ctx context.Context, version string) error {
+	str := s.GoInstallCmd
+	if str == "" {
+		str = defaultGoInstallCmd
+	}
+	str = strings.ReplaceAll(str, "{version}", version)
+	var cmd *exec.Cmd
+	if isWindows() {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", str)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", str)
+	}
+	// 在 home 目录执行，并禁用 bas 的 Spec，避免 go 命令再次触发安装
+	cmd.Dir = homeDir
+	cmd.Env = append(os.Environ(), envKey("NoHook")+"=true", "GOTOOLCHAIN=local")
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	log.Println("install go:", str)
+	return cmd.Run()
+}
+
+// installFromDir 从本地目录(如镜像目录)中的安装包安装，
+// 安装包的文件名和官方的一致，如 go1.21.5.linux-amd64.tar.gz
+func (s *specGo) installFromDir(version string) error {
+	fileName := fmt.Sprintf("go%s.%s-%s.tar.gz", version, runtime.GOOS, runtime.GOARCH)
+	src := filepath.Join(expandHome(s.GoInstallDir), fileName)
+	dst := filepath.Join(s.sdkDir(), "go"+version)
+	log.Printf("install go from %q to %q\n", src, dst)
+
+	// 先解压到临时目录，成功后再重命名，避免留下不完整的安装目录
+	tmp := dst + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := extractTarGz(src, tmp, "go/"); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// extractTarGz 解压 tar.gz 文件到 dst 目录，并去掉文件名中的前缀 trimPrefix
+func extractTarGz(src string, dst string, trimPrefix string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", src, err)
+		}
+		name := strings.TrimPrefix(hdr.Name, trimPrefix)
+		if name == "" || !filepath.IsLocal(name) {
+			continue
+		}
+		fp := filepath.Join(dst, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(fp, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = writeFileFrom(fp, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			// go 的安装包中没有链接，链接可能指向 dst 之外，让后面的文件通过链接写到 dst 之外
+			return fmt.Errorf("%s: link %q -> %q is not supported", src, hdr.Name, hdr.Linkname)
+		}
+	}
+}
+
+func writeFileFrom(fp string, r io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+var (
+	// lockStale 锁文件超过此时间未更新，认为持有锁的进程已异常退出
+	lockStale = 2 * time.Minute
+
+	// lockRefresh 持有锁期间，更新锁文件修改时间的间隔，需要远小于 lockStale，
+	// 避免安装较慢时，锁被其他进程认为已过期
+	lockRefresh = 20 * time.Second
+)
+
+// lockFile 使用文件实现的进程间的锁，用于避免多个进程同时安装
+func lockFile(ctx context.Context, fp string) (unlock func(), err error) {
+	if err = os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		f, err := os.OpenFile(fp, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d", os.Getpid())
+			_ = f.Close()
+			stop := keepLockFresh(fp)
+			return func() {
+				stop()
+				_ = os.Remove(fp)
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if st, err1 := os.Stat(fp); err1 == nil && time.Since(st.ModTime()) > lockStale {
+			log.Printf("lock %q is stale, remove it\n", fp)
+			_ = os.Remove(fp)
+			continue
+		}
+		if i == 0 {
+			log.Printf("waiting for lock %q\n", fp)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// keepLockFresh 定期更新锁文件的修改时间，直到调用返回的 stop
+func keepLockFresh(fp string) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		tk := time.NewTicker(lockRefresh)
+		defer tk.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tk.C:
+				now := time.Now()
+				_ = os.Chtimes(fp, now, now)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
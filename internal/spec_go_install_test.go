@@ -0,0 +1,194 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpecGo_installFromDir(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	setDataDir(t, t.TempDir())
+	mirror := t.TempDir()
+	sdk := t.TempDir()
+
+	fileName := fmt.Sprintf("go1.21.5.%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	writeTarGz(t, filepath.Join(mirror, fileName), map[string]string{
+		"go/VERSION": "go1.21.5",
+		"go/bin/go":  "#!/bin/sh\necho go1.21.5\n",
+	})
+
+	s := &specGo{GoInstall: "auto", GoInstallDir: mirror, GoSDKDir: sdk}
+	r := &Rule{Cmd: "go"}
+	ok, err := s.tryInstall(r, "go1.21.5")
+	if err != nil || !ok {
+		t.Fatalf("tryInstall() = %v, %v", ok, err)
+	}
+	want := filepath.Join(sdk, "go1.21.5", "bin", "go")
+	if r.Cmd != want {
+		t.Fatalf("Cmd = %q, want %q", r.Cmd, want)
+	}
+	if !isExecutable(want) {
+		t.Fatalf("%q is not executable", want)
+	}
+
+	// 已安装的，不会再次安装
+	if err = os.Remove(filepath.Join(mirror, fileName)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.installGo(context.Background(), "go1.21.5")
+	if err != nil || got != want {
+		t.Fatalf("installGo() = %q, %v", got, err)
+	}
+
+	if _, err = s.tryInstall(r, "go1.20.1"); err == nil {
+		t.Fatal("expect error when the tarball not exists")
+	}
+	if _, err = os.Stat(filepath.Join(sdk, "go1.20.1")); !os.IsNotExist(err) {
+		t.Fatalf("expect no go1.20.1 dir, got %v", err)
+	}
+}
+
+func TestGoReleaseName(t *testing.T) {
+	tests := map[string]string{
+		"1.20":    "go1.20",
+		"1.21":    "go1.21.0",
+		"1.22.3":  "go1.22.3",
+		"1.23rc1": "go1.23rc1",
+		"1.19.13": "go1.19.13",
+		"1.24.0":  "go1.24.0",
+	}
+	for in, want := range tests {
+		if got := goReleaseName(in); got != want {
+			t.Errorf("goReleaseName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func writeTarGz(t *testing.T, fp string, files map[string]string) {
+	f, err := os.Create(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err = tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractTarGz_link(t *testing.T) {
+	outside := t.TempDir()
+	for _, typ := range []byte{tar.TypeSymlink, tar.TypeLink} {
+		// 先创建指向 dst 之外的链接，再通过链接写入文件
+		src := filepath.Join(t.TempDir(), "go.tar.gz")
+		f, err := os.Create(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gw := gzip.NewWriter(f)
+		tw := tar.NewWriter(gw)
+		hdrs := []*tar.Header{
+			{Name: "go/bin/", Mode: 0755, Typeflag: tar.TypeDir},
+			{Name: "go/bin/evil", Linkname: outside, Typeflag: typ},
+			{Name: "go/bin/evil/pwn", Mode: 0755, Size: 3, Typeflag: tar.TypeReg},
+		}
+		for _, hdr := range hdrs {
+			if err = tw.WriteHeader(hdr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err = tw.Write([]byte("pwn")); err != nil {
+			t.Fatal(err)
+		}
+		if err = tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err = gw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err = f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		dst := t.TempDir()
+		err = extractTarGz(src, dst, "go/")
+		if err == nil || !strings.Contains(err.Error(), "is not supported") {
+			t.Errorf("extractTarGz() type %c error = %v, want link not supported", typ, err)
+		}
+		if _, err = os.Lstat(filepath.Join(outside, "pwn")); err == nil {
+			t.Fatalf("extractTarGz() type %c wrote file outside of dst", typ)
+		}
+		if _, err = os.Lstat(filepath.Join(dst, "bin", "evil")); err == nil {
+			t.Errorf("extractTarGz() type %c created the link", typ)
+		}
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	oldStale, oldRefresh := lockStale, lockRefresh
+	lockStale, lockRefresh = 500*time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() {
+		lockStale, lockRefresh = oldStale, oldRefresh
+	})
+	fp := filepath.Join(t.TempDir(), "go1.21.5.lock")
+
+	unlock, err := lockFile(context.Background(), fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 持有锁的时间超过了 lockStale，但是锁文件一直在更新，其他的不能获取到锁
+	ctx, cancel := context.WithTimeout(context.Background(), 3*lockStale)
+	defer cancel()
+	if _, err = lockFile(ctx, fp); err == nil {
+		t.Fatal("expect timeout when the lock is held")
+	}
+	unlock()
+
+	unlock, err = lockFile(context.Background(), fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	// 未更新的锁文件，认为已过期
+	if err = os.WriteFile(fp, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * lockStale)
+	if err = os.Chtimes(fp, old, old); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), lockStale/2)
+	defer cancel()
+	unlock, err = lockFile(ctx, fp)
+	if err != nil {
+		t.Fatalf("expect to take over the stale lock, got %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,278 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// specJava java、javac、mvn、gradle 等命令的 Spec
+type specJava struct {
+	specCommon
+
+	// JavaVersionFile 定义 java 版本的文件，支持：
+	// auto: 依次查找 .java-version、.sdkmanrc、pom.xml、build.gradle.kts、build.gradle
+	// 或者只使用其中一个
+	// "", no: 跳过
+	JavaVersionFile string `json:",omitempty"`
+
+	// JDKRoots JDK 的安装目录，可选，目录下的每个子目录为一个 JDK，
+	// 默认为 ~/.sdkman/candidates/java、/usr/lib/jvm、/Library/Java/JavaVirtualMachines、~/.jdks
+	JDKRoots []string `json:",omitempty"`
+
+	// binName 当前命令，如 java、mvn
+	binName string
+}
+
+func newSpecJava(name string) specParser {
+	return &specJava{binName: name}
+}
+
+var javaVersionFiles = []string{".java-version", ".sdkmanrc", "pom.xml", "build.gradle.kts", "build.gradle"}
+
+func (s *specJava) Check() error {
+	switch s.JavaVersionFile {
+	case "", "no", "auto":
+		return nil
+	}
+	if !slices.Contains(javaVersionFiles, s.JavaVersionFile) {
+		return fmt.Errorf("not support JavaVersionFile=%q, now support 'auto', %q", s.JavaVersionFile, javaVersionFiles)
+	}
+	return nil
+}
+
+func (s *specJava) Parser(r *Rule) error {
+	if err := convertByJSON(r.Spec, s); err != nil {
+		return err
+	}
+	if err := s.Check(); err != nil {
+		return err
+	}
+	if tp := r.toolPick; tp != nil {
+		// asdf、mise 中 java 的安装目录为 {version}/bin/java
+		if tp.Bin != "" {
+			s.useJDK(r, filepath.Dir(filepath.Dir(tp.Bin)))
+		}
+		return nil
+	}
+	if r.Version != "" {
+		return s.useVersion(r, r.Version, "Rule.Version")
+	}
+	if s.JavaVersionFile == "" || s.JavaVersionFile == "no" {
+		return nil
+	}
+	fp, want, err := s.findVersion()
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	r.decide("java version %q defined in %q", want, fp)
+	return s.useVersion(r, want, fp)
+}
+
+// useVersion 使用满足版本要求 want 的 JDK，from 为定义版本的地方
+func (s *specJava) useVersion(r *Rule, want string, from string) error {
+	javaHome, err := s.findJDK(want)
+	if err != nil {
+		return fmt.Errorf("%s: %w", from, err)
+	}
+	if javaHome == "" {
+		return s.missing(r, &specMissError{
+			Bin:      "JDK",
+			Want:     want,
+			From:     from,
+			Searched: s.jdkRoots(),
+			Hint:     fmt.Sprintf("install it by 'sdk install java %s', or set JDKRoots", want),
+		})
+	}
+	s.useJDK(r, javaHome)
+	return nil
+}
+
+// useJDK 设置 JAVA_HOME，java、javac 等 JDK 中有的命令，直接使用 JDK 中的
+func (s *specJava) useJDK(r *Rule, javaHome string) {
+	binDir := filepath.Join(javaHome, "bin")
+	r.Env = append(r.Env, "JAVA_HOME="+javaHome)
+	r.Env = prependPathEnv(r.Env, binDir)
+	if bp := filepath.Join(binDir, s.binName); isExecutable(bp) {
+		r.Cmd = bp
+	}
+	r.decide("using JAVA_HOME=%q, Cmd = %q", javaHome, r.Cmd)
+}
+
+// findJDK 查找满足版本要求的 JDK，返回 JAVA_HOME，找不到时返回空
+func (s *specJava) findJDK(want string) (string, error) {
+	homes := s.allJDKs()
+	// 优先使用目录名完全一致的，如 sdkman 的 17.0.2-tem
+	for _, h := range homes {
+		if h.Name == want {
+			return h.Home, nil
+		}
+	}
+	// 如 "17"、"17.0.2-tem"、"temurin-17.0.2"
+	vs := javaDirNameReg.FindString(want)
+	if vs == "" {
+		return "", fmt.Errorf("invalid java version %q", want)
+	}
+	vc, err := parseConstraint(normalizeJavaVersion(vs))
+	if err != nil {
+		return "", err
+	}
+	var best *jdkHome
+	for i, h := range homes {
+		if !vc.Check(h.Version) {
+			continue
+		}
+		if best == nil || h.Version.Compare(best.Version) > 0 {
+			best = &homes[i]
+		}
+	}
+	if best == nil {
+		return "", nil
+	}
+	return best.Home, nil
+}
+
+type jdkHome struct {
+	Name    string // 目录名
+	Home    string // JAVA_HOME
+	Version semVersion
+}
+
+func (s *specJava) allJDKs() []jdkHome {
+	var result []jdkHome
+	for _, root := range s.jdkRoots() {
+		watchPath(root)
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			home := filepath.Join(root, e.Name())
+			// macOS 的 JDK 在 Contents/Home 下
+			if mh := filepath.Join(home, "Contents", "Home"); isExecutable(filepath.Join(mh, "bin", "java")) {
+				home = mh
+			}
+			if !isExecutable(filepath.Join(home, "bin", "java")) {
+				continue
+			}
+			v, ok := jdkVersion(home, e.Name())
+			if !ok {
+				continue
+			}
+			result = append(result, jdkHome{Name: e.Name(), Home: home, Version: v})
+		}
+	}
+	return result
+}
+
+var (
+	javaReleaseReg = regexp.MustCompile(`(?m)^JAVA_VERSION="?([^"\s]+)"?`)
+	javaDirNameReg = regexp.MustCompile(`(1\.8|\d+(?:\.\d+){0,2})`)
+)
+
+// jdkVersion 读取 JDK 的版本，优先使用 {JAVA_HOME}/release 文件，否则从目录名中解析
+func jdkVersion(home string, dirName string) (semVersion, bool) {
+	if content, err := os.ReadFile(filepath.Join(home, "release")); err == nil {
+		if m := javaReleaseReg.FindSubmatch(content); m != nil {
+			if v, ok := parseVersion(normalizeJavaVersion(string(m[1]))); ok {
+				return v, true
+			}
+		}
+	}
+	m := javaDirNameReg.FindString(dirName)
+	if m == "" {
+		return semVersion{}, false
+	}
+	return parseVersion(normalizeJavaVersion(m))
+}
+
+// normalizeJavaVersion 将 java 8 及之前的版本号 "1.8.0_292" 转换为 "8.0.292"，
+// 并去掉 sdkman 版本中的发行商，如 "17.0.2-tem" -> "17.0.2"
+func normalizeJavaVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if idx := strings.IndexAny(v, "-+"); idx > 0 {
+		v = v[:idx]
+	}
+	v = strings.ReplaceAll(v, "_", ".")
+	if after, ok := strings.CutPrefix(v, "1."); ok && after != "" {
+		v = after
+	}
+	return v
+}
+
+func (s *specJava) jdkRoots() []string {
+	if len(s.JDKRoots) > 0 {
+		return expandHomeAll(s.JDKRoots)
+	}
+	sdkman := cmp.Or(getenv("SDKMAN_DIR"), filepath.Join(homeDir, ".sdkman"))
+	return []string{
+		filepath.Join(sdkman, "candidates", "java"),
+		"/usr/lib/jvm",
+		"/Library/Java/JavaVirtualMachines",
+		filepath.Join(homeDir, ".jdks"),
+	}
+}
+
+var (
+	sdkmanJavaReg = regexp.MustCompile(`(?m)^\s*java\s*=\s*(\S+)`)
+	pomJavaRegs   = []*regexp.Regexp{
+		regexp.MustCompile(`<maven\.compiler\.release>\s*([^<\s$]+)\s*<`),
+		regexp.MustCompile(`<release>\s*([^<\s$]+)\s*</release>`),
+		regexp.MustCompile(`<java\.version>\s*([^<\s$]+)\s*<`),
+		regexp.MustCompile(`<maven\.compiler\.source>\s*([^<\s$]+)\s*<`),
+	}
+	gradleJavaRegs = []*regexp.Regexp{
+		regexp.MustCompile(`JavaLanguageVersion\.of\(\s*["']?(\d+)["']?\s*\)`),
+		regexp.MustCompile(`sourceCompatibility\s*=\s*JavaVersion\.VERSION_(\d+(?:_\d+)?)`),
+		regexp.MustCompile(`sourceCompatibility\s*=\s*["']?(\d+(?:\.\d+)?)["']?`),
+	}
+)
+
+// findVersion 查找定义 java 版本的文件，返回文件路径和版本
+func (s *specJava) findVersion() (string, string, error) {
+	names := javaVersionFiles
+	if s.JavaVersionFile != "auto" {
+		names = []string{s.JavaVersionFile}
+	}
+	for _, name := range names {
+		fp, err := findFileUpper(name, 128)
+		if err != nil {
+			if errors.Is(err, errFileNotFound) {
+				continue
+			}
+			return "", "", err
+		}
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			return "", "", err
+		}
+		var regs []*regexp.Regexp
+		switch name {
+		case ".java-version":
+			return fp, firstLine(content), nil
+		case ".sdkmanrc":
+			regs = []*regexp.Regexp{sdkmanJavaReg}
+		case "pom.xml":
+			regs = pomJavaRegs
+		default:
+			regs = gradleJavaRegs
+		}
+		for _, reg := range regs {
+			if m := reg.FindSubmatch(content); m != nil {
+				return fp, strings.ReplaceAll(string(m[1]), "_", "."), nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("%w: %q", errFileNotFound, names)
+}
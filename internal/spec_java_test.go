@@ -0,0 +1,100 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeJavaVersion(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{v: "1.8", want: "8"},
+		{v: "1.8.0_292", want: "8.0.292"},
+		{v: "8", want: "8"},
+		{v: "11", want: "11"},
+		{v: "17.0.2", want: "17.0.2"},
+		{v: "17.0.2-tem", want: "17.0.2"},
+		{v: "17.0.2+8", want: "17.0.2"},
+		{v: " 21 ", want: "21"},
+	}
+	for _, tt := range tests {
+		if got := normalizeJavaVersion(tt.v); got != tt.want {
+			t.Errorf("normalizeJavaVersion(%q) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestSpecJava_findJDK(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	root := t.TempDir()
+	for _, name := range []string{"8.0.292-tem", "17.0.2-tem", "temurin-17.0.9", "21.0.1-open"} {
+		writeExec(t, filepath.Join(root, name, "bin", "java"))
+	}
+	// 目录名中没有版本号，使用 release 文件中的
+	writeExec(t, filepath.Join(root, "default", "bin", "java"))
+	writeFile(t, filepath.Join(root, "default", "release"), "JAVA_VERSION=\"1.8.0_392\"\n")
+
+	s := &specJava{JDKRoots: []string{root}}
+	tests := []struct {
+		want string
+		home string
+	}{
+		{want: "17.0.2-tem", home: "17.0.2-tem"},
+		{want: "17", home: "temurin-17.0.9"},
+		{want: "17.0.2", home: "17.0.2-tem"},
+		{want: "1.8", home: "default"},
+		{want: "8", home: "default"},
+		{want: "21", home: "21.0.1-open"},
+		{want: "11"},
+	}
+	for _, tt := range tests {
+		got, err := s.findJDK(tt.want)
+		if err != nil {
+			t.Fatalf("findJDK(%q) error = %v", tt.want, err)
+		}
+		var want string
+		if tt.home != "" {
+			want = filepath.Join(root, tt.home)
+		}
+		if got != want {
+			t.Errorf("findJDK(%q) = %q, want %q", tt.want, got, want)
+		}
+	}
+}
+
+func TestSpecJava_findVersion(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    string
+	}{
+		{file: ".java-version", content: "17.0.2-tem\n", want: "17.0.2-tem"},
+		{file: ".sdkmanrc", content: "# comment\njava=21.0.1-open\n", want: "21.0.1-open"},
+		{file: "pom.xml", content: "<properties><maven.compiler.release>17</maven.compiler.release></properties>", want: "17"},
+		{file: "pom.xml", content: "<properties><java.version>1.8</java.version></properties>", want: "1.8"},
+		{file: "build.gradle.kts", content: "java { toolchain { languageVersion.set(JavaLanguageVersion.of(21)) } }", want: "21"},
+		{file: "build.gradle", content: "sourceCompatibility = JavaVersion.VERSION_1_8", want: "1.8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, tt.file), tt.content)
+			t.Chdir(dir)
+			s := &specJava{JavaVersionFile: "auto"}
+			fp, got, err := s.findVersion()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || filepath.Base(fp) != tt.file {
+				t.Errorf("findVersion() = %q, %q, want %q", fp, got, tt.want)
+			}
+		})
+	}
+}
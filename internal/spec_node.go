@@ -0,0 +1,201 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// specNode node、npm、npx 命令的 Spec
+type specNode struct {
+	specCommon
+
+	// NodeVersionFile 定义 node 版本的文件，支持：
+	// auto: 依次查找 .nvmrc、.node-version、package.json(engines.node)，使用最先找到的
+	// .nvmrc、.node-version、package.json: 只使用指定的文件
+	// "", no: 跳过
+	NodeVersionFile string
+
+	// InstallDirs node 的安装目录，可选，目录下的每个子目录为一个版本，
+	// 默认为 nvm、fnm、volta 的安装目录，如 ~/.nvm/versions/node
+	InstallDirs []string `json:",omitempty"`
+
+	// binName 当前命令，如 node、npm
+	binName string
+}
+
+func newSpecNode(name string) specParser {
+	return &specNode{binName: name}
+}
+
+var nodeVersionFiles = []string{".nvmrc", ".node-version", "package.json"}
+
+func (s *specNode) Check() error {
+	if s.NodeVersionFile == "" || s.NodeVersionFile == "no" || s.NodeVersionFile == "auto" {
+		return nil
+	}
+	if !slices.Contains(nodeVersionFiles, s.NodeVersionFile) {
+		return fmt.Errorf("not support NodeVersionFile=%q, now support 'auto', %q", s.NodeVersionFile, nodeVersionFiles)
+	}
+	return nil
+}
+
+func (s *specNode) Parser(r *Rule) error {
+	if err := convertByJSON(r.Spec, s); err != nil {
+		return err
+	}
+	if err := s.Check(); err != nil {
+		return err
+	}
+	if r.toolPick != nil {
+		return nil
+	}
+	if r.Version != "" {
+		return s.useVersion(r, r.Version, "Rule.Version")
+	}
+	if s.NodeVersionFile == "" || s.NodeVersionFile == "no" {
+		return nil
+	}
+
+	fp, want, err := s.findVersion()
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	r.decide("node version %q defined in %q", want, fp)
+	return s.useVersion(r, want, fp)
+}
+
+// useVersion 使用满足版本要求 want 的 node，from 为定义版本的地方
+func (s *specNode) useVersion(r *Rule, want string, from string) error {
+	miss := &specMissError{
+		Bin:      s.binName,
+		Want:     want,
+		From:     from,
+		Searched: append(s.installDirs(), "$PATH"),
+		Hint:     fmt.Sprintf("install it by 'nvm install %s', or set InstallDirs", want),
+	}
+	vc, err := nodeConstraint(want)
+	if err != nil {
+		// nvm 支持的 lts/-1、iojs 等别名，当作没有找到，而不是让命令不能执行
+		r.decide("node version %q is not supported: %v", want, err)
+		return s.missing(r, miss)
+	}
+
+	// 优先使用安装目录中的，此时可以同时修改 PATH，让 npm 等脚本也使用对应版本的 node
+	vr := &versionResolver{
+		InstallDirs: s.installDirs(),
+		Rels: []string{
+			filepath.Join("bin", s.binName),
+			filepath.Join("installation", "bin", s.binName), // fnm
+		},
+		PathPrefix: s.binName,
+	}
+	if b, ok := vr.Resolve(vc, r.Trace); ok {
+		useVersionedBin(r, s.binName, b)
+		return nil
+	}
+	return s.missing(r, miss)
+}
+
+// findVersion 查找定义 node 版本的文件，返回文件路径和版本
+func (s *specNode) findVersion() (string, string, error) {
+	names := nodeVersionFiles
+	if s.NodeVersionFile != "auto" {
+		names = []string{s.NodeVersionFile}
+	}
+	for _, name := range names {
+		fp, err := findFileUpper(name, 128)
+		if err != nil {
+			if errors.Is(err, errFileNotFound) {
+				continue
+			}
+			return "", "", err
+		}
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			return "", "", err
+		}
+		if name != "package.json" {
+			return fp, firstLine(content), nil
+		}
+		var pkg struct {
+			Engines struct {
+				Node string `json:"node"`
+			} `json:"engines"`
+		}
+		if err = json.Unmarshal(content, &pkg); err != nil {
+			return "", "", fmt.Errorf("parser %q: %w", fp, err)
+		}
+		if pkg.Engines.Node != "" {
+			return fp, pkg.Engines.Node, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %q", errFileNotFound, names)
+}
+
+func (s *specNode) installDirs() []string {
+	if len(s.InstallDirs) > 0 {
+		return expandHomeAll(s.InstallDirs)
+	}
+	nvmDir := cmp.Or(getenv("NVM_DIR"), filepath.Join(homeDir, ".nvm"))
+	dirs := []string{filepath.Join(nvmDir, "versions", "node")}
+	if dir := getenv("FNM_DIR"); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, "node-versions"))
+	}
+	dirs = append(dirs,
+		filepath.Join(homeDir, ".local", "share", "fnm", "node-versions"),
+		filepath.Join(homeDir, "Library", "Application Support", "fnm", "node-versions"),
+		filepath.Join(cmp.Or(getenv("VOLTA_HOME"), filepath.Join(homeDir, ".volta")), "tools", "image", "node"),
+	)
+	return dirs
+}
+
+// nodeLTSNames node LTS 版本的代号
+var nodeLTSNames = map[string]int{
+	"argon":    4,
+	"boron":    6,
+	"carbon":   8,
+	"dubnium":  10,
+	"erbium":   12,
+	"fermium":  14,
+	"gallium":  16,
+	"hydrogen": 18,
+	"iron":     20,
+	"jod":      22,
+	"krypton":  24,
+}
+
+// nodeConstraint 将 .nvmrc 等文件中的版本转换为版本约束，
+// 除了 semver 范围外，还支持 nvm 的别名：node、stable、latest、lts/*、lts/hydrogen
+func nodeConstraint(str string) (versionConstraint, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	switch str {
+	case "node", "stable", "latest", "current":
+		return parseConstraint("*")
+	case "lts/*", "lts":
+		var majors []string
+		for _, v := range nodeLTSNames {
+			majors = append(majors, fmt.Sprint(v))
+		}
+		return parseConstraint(strings.Join(majors, " || "))
+	}
+	if name, ok := strings.CutPrefix(str, "lts/"); ok {
+		major, found := nodeLTSNames[name]
+		if !found {
+			return nil, fmt.Errorf("unknown node lts version %q", str)
+		}
+		return parseConstraint(fmt.Sprint(major))
+	}
+	return parseConstraint(str)
+}
@@ -0,0 +1,273 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNodeConstraint(t *testing.T) {
+	tests := []struct {
+		str     string
+		match   []string
+		noMatch []string
+		wantErr bool
+	}{
+		{
+			str:     "18",
+			match:   []string{"18.0.0", "18.19.0"},
+			noMatch: []string{"17.9.1", "20.11.0"},
+		},
+		{
+			str:     "v18.17.0",
+			match:   []string{"18.17.0"},
+			noMatch: []string{"18.17.1"},
+		},
+		{
+			str:     ">=16 <20",
+			match:   []string{"16.0.0", "19.9.0"},
+			noMatch: []string{"20.0.0"},
+		},
+		{
+			str:   "node",
+			match: []string{"4.0.0", "21.6.1"},
+		},
+		{
+			str:     "lts/*",
+			match:   []string{"18.19.0", "20.11.0", "22.1.0"},
+			noMatch: []string{"19.0.0", "21.6.1"},
+		},
+		{
+			str:     "lts/hydrogen",
+			match:   []string{"18.19.0"},
+			noMatch: []string{"20.11.0"},
+		},
+		{
+			str:     " LTS/Iron\n",
+			match:   []string{"20.11.0"},
+			noMatch: []string{"18.19.0"},
+		},
+		{
+			str:     "lts/unknown",
+			wantErr: true,
+		},
+		{
+			str:     "lts/-1",
+			wantErr: true,
+		},
+		{
+			str:     "iojs",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			vc, err := nodeConstraint(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nodeConstraint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, str := range tt.match {
+				if v, _ := parseVersion(str); !vc.Check(v) {
+					t.Errorf("Check(%q) = false, want true", str)
+				}
+			}
+			for _, str := range tt.noMatch {
+				if v, _ := parseVersion(str); vc.Check(v) {
+					t.Errorf("Check(%q) = true, want false", str)
+				}
+			}
+		})
+	}
+}
+
+func TestSpecNode_findVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		files           map[string]string
+		nodeVersionFile string
+		wantFile        string
+		want            string
+		wantErr         bool
+	}{
+		{
+			name: ".nvmrc first",
+			files: map[string]string{
+				".nvmrc":        "# comment\nlts/hydrogen\n",
+				".node-version": "20\n",
+			},
+			nodeVersionFile: "auto",
+			wantFile:        ".nvmrc",
+			want:            "lts/hydrogen",
+		},
+		{
+			name: ".node-version",
+			files: map[string]string{
+				".node-version": "20.11.0\n",
+				"package.json":  `{"engines":{"node":">=18"}}`,
+			},
+			nodeVersionFile: "auto",
+			wantFile:        ".node-version",
+			want:            "20.11.0",
+		},
+		{
+			name: "package.json engines.node",
+			files: map[string]string{
+				"package.json": `{"name":"x","engines":{"node":">=18 <21"}}`,
+			},
+			nodeVersionFile: "auto",
+			wantFile:        "package.json",
+			want:            ">=18 <21",
+		},
+		{
+			name: "package.json without engines.node",
+			files: map[string]string{
+				"package.json": `{"name":"x"}`,
+			},
+			nodeVersionFile: "auto",
+			wantErr:         true,
+		},
+		{
+			name: "only the given file",
+			files: map[string]string{
+				".nvmrc":       "18\n",
+				"package.json": `{"engines":{"node":"20"}}`,
+			},
+			nodeVersionFile: "package.json",
+			wantFile:        "package.json",
+			want:            "20",
+		},
+		{
+			name: "invalid package.json",
+			files: map[string]string{
+				"package.json": `{"engines":`,
+			},
+			nodeVersionFile: "package.json",
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			// 在子目录中执行，版本文件在上级目录
+			sub := filepath.Join(dir, "sub")
+			writeFile(t, filepath.Join(sub, "x.js"), "")
+			t.Chdir(sub)
+
+			s := &specNode{NodeVersionFile: tt.nodeVersionFile}
+			fp, got, err := s.findVersion()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := filepath.Join(dir, tt.wantFile); fp != want {
+				t.Errorf("findVersion() file = %q, want %q", fp, want)
+			}
+			if got != tt.want {
+				t.Errorf("findVersion() version = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecNode_Parser(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	home := t.TempDir()
+	setHomeDir(t, home)
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("NVM_DIR", "")
+	t.Setenv("VOLTA_HOME", "")
+	fnmDir := t.TempDir()
+	t.Setenv("FNM_DIR", fnmDir)
+
+	fnmNode := filepath.Join(fnmDir, "node-versions", "v20.11.0", "installation", "bin", "node")
+	writeExec(t, fnmNode)
+	voltaNode := filepath.Join(home, ".volta", "tools", "image", "node", "18.19.0", "bin", "node")
+	writeExec(t, voltaNode)
+	nvmNode := filepath.Join(home, ".nvm", "versions", "node", "v16.20.2", "bin", "node")
+	writeExec(t, nvmNode)
+
+	tests := []struct {
+		name    string
+		nvmrc   string
+		spec    map[string]any
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "fnm",
+			nvmrc: "lts/iron\n",
+			want:  fnmNode,
+		},
+		{
+			name:  "volta",
+			nvmrc: "lts/hydrogen\n",
+			want:  voltaNode,
+		},
+		{
+			name:  "nvm",
+			nvmrc: "16\n",
+			want:  nvmNode,
+		},
+		{
+			name:  "newest lts",
+			nvmrc: "lts/*\n",
+			want:  fnmNode,
+		},
+		{
+			name:  "unsupported alias",
+			nvmrc: "lts/-1\n",
+			want:  "node",
+		},
+		{
+			name:  "iojs",
+			nvmrc: "iojs\n",
+			want:  "node",
+		},
+		{
+			name:    "unsupported alias with Strict",
+			nvmrc:   "lts/-1\n",
+			spec:    map[string]any{"Strict": true},
+			wantErr: true,
+		},
+		{
+			name:  "not installed",
+			nvmrc: "21\n",
+			want:  "node",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".nvmrc"), tt.nvmrc)
+			t.Chdir(dir)
+
+			spec := map[string]any{"NodeVersionFile": "auto"}
+			maps.Copy(spec, tt.spec)
+			r := &Rule{Cmd: "node", Spec: spec}
+			err := getSpecParser("node").Parser(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if r.Cmd != tt.want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, tt.want)
+			}
+			if tt.want != "node" && !strings.HasPrefix(r.Env[0], "PATH="+filepath.Dir(tt.want)) {
+				t.Errorf("Env = %q, want PATH starts with %q", r.Env, filepath.Dir(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xanygo/anygo/xcfg"
+)
+
+// specPython python、pip、pytest 等命令的 Spec
+type specPython struct {
+	specCommon
+
+	// PythonVersionFile 定义 python 版本的文件，支持：
+	// auto: 依次查找 .python-version、pyproject.toml(project.requires-python 或 tool.poetry.dependencies.python)
+	// .python-version、pyproject.toml: 只使用指定的文件
+	// "", no: 跳过
+	PythonVersionFile string `json:",omitempty"`
+
+	// InstallDirs python 的安装目录，可选，目录下的每个子目录为一个版本，默认为 pyenv 的 ~/.pyenv/versions
+	InstallDirs []string `json:",omitempty"`
+
+	// Venv 是否使用虚拟环境，支持：
+	// auto: 在当前目录以及上级目录查找虚拟环境(VenvNames)，找到后使用虚拟环境中的命令，
+	// 并设置环境变量 VIRTUAL_ENV 和 PATH
+	// "", no: 跳过
+	Venv string `json:",omitempty"`
+
+	// VenvNames 虚拟环境的目录名，可选，默认为 [".venv", "venv"]
+	VenvNames []string `json:",omitempty"`
+
+	// binName 当前命令，如 python、pip
+	binName string
+}
+
+func newSpecPython(name string) specParser {
+	return &specPython{binName: name}
+}
+
+var pythonVersionFiles = []string{".python-version", "pyproject.toml"}
+
+func (s *specPython) Check() error {
+	switch s.PythonVersionFile {
+	case "", "no", "auto":
+	default:
+		if !slices.Contains(pythonVersionFiles, s.PythonVersionFile) {
+			return fmt.Errorf("not support PythonVersionFile=%q, now support 'auto', %q", s.PythonVersionFile, pythonVersionFiles)
+		}
+	}
+	if !slices.Contains([]string{"", "no", "auto"}, s.Venv) {
+		return fmt.Errorf("not support Venv=%q", s.Venv)
+	}
+	return nil
+}
+
+func (s *specPython) Parser(r *Rule) error {
+	if err := convertByJSON(r.Spec, s); err != nil {
+		return err
+	}
+	if err := s.Check(); err != nil {
+		return err
+	}
+	// 虚拟环境中 python 的版本是固定的，优先使用虚拟环境
+	if s.Venv == "auto" {
+		ok, err := s.useVenv(r)
+		if ok || err != nil {
+			return err
+		}
+	}
+	if r.toolPick != nil {
+		return nil
+	}
+	if r.Version != "" {
+		return s.useVersion(r, r.Version, "Rule.Version", r.Version)
+	}
+	return s.pythonVersionFile(r)
+}
+
+// useVenv 查找并使用虚拟环境，找到时返回 true
+func (s *specPython) useVenv(r *Rule) (bool, error) {
+	names := s.VenvNames
+	if len(names) == 0 {
+		names = []string{".venv", "venv"}
+	}
+	binDir := "bin"
+	if isWindows() {
+		binDir = "Scripts"
+	}
+	for _, name := range names {
+		// 虚拟环境的目录中都有 pyvenv.cfg 文件
+		fp, err := findFileUpper(filepath.Join(name, "pyvenv.cfg"), 128)
+		if err != nil {
+			if errors.Is(err, errFileNotFound) {
+				continue
+			}
+			return false, err
+		}
+		venv := filepath.Dir(fp)
+		bp := filepath.Join(venv, binDir, s.binName)
+		if isExecutable(bp) {
+			r.Cmd = bp
+		}
+		r.Env = prependPathEnv(r.Env, filepath.Join(venv, binDir))
+		r.Env = append(r.Env, "VIRTUAL_ENV="+venv)
+		r.decide("using virtualenv %q, Cmd = %q", venv, r.Cmd)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *specPython) pythonVersionFile(r *Rule) error {
+	if s.PythonVersionFile == "" || s.PythonVersionFile == "no" {
+		return nil
+	}
+	fp, want, err := s.findVersion()
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	r.decide("python version %q defined in %q", want, fp)
+	if filepath.Base(fp) == ".python-version" {
+		// pyenv 的版本文件中可以定义多个版本，如 "3.12.1 3.11.7"，依次尝试
+		return s.useVersion(r, want, fp, strings.Fields(want)...)
+	}
+	return s.useVersion(r, want, fp, want)
+}
+
+// useVersion 依次尝试 versions 中的版本，使用第一个找到的 python，want 和 from 为定义的版本和定义版本的地方
+func (s *specPython) useVersion(r *Rule, want string, from string, versions ...string) error {
+	for _, version := range versions {
+		if version == "system" {
+			r.decide("python version is system, using %q", r.Cmd)
+			return nil
+		}
+		if s.findBin(r, version) {
+			return nil
+		}
+	}
+	return s.missing(r, &specMissError{
+		Bin:      s.binName,
+		Want:     want,
+		From:     from,
+		Searched: append(s.installDirs(), "$PATH"),
+		Hint:     "install it by pyenv, or set InstallDirs",
+	})
+}
+
+// findBin 查找并使用版本为 version 的 python，找到时返回 true
+func (s *specPython) findBin(r *Rule, version string) bool {
+	// pyenv 的安装目录以版本命名，如 pypy3.10-7.3.12、miniconda3-latest，这些不是语义化的版本号
+	if checkCmdVersion(version) == nil {
+		for _, dir := range s.installDirs() {
+			if bp := filepath.Join(dir, version, "bin", s.binName); isExecutable(bp) {
+				r.Cmd = bp
+				r.Env = prependPathEnv(r.Env, filepath.Dir(bp))
+				r.decide("using %s %s: %q", s.binName, version, bp)
+				return true
+			}
+		}
+	}
+	vc, err := parseConstraint(version)
+	if err != nil {
+		r.decide("python version %q is not installed and is not a version constraint: %v", version, err)
+		return false
+	}
+	vr := &versionResolver{
+		InstallDirs: s.installDirs(),
+		Rels:        []string{filepath.Join("bin", s.binName)},
+		// 如 python3.12、pip3.12，只有 python3 这种的不使用，因为不能确定其具体的版本
+		PathPrefix: strings.TrimRight(s.binName, "0123456789"),
+		MinParts:   2,
+	}
+	b, ok := vr.Resolve(vc, r.Trace)
+	if ok {
+		useVersionedBin(r, s.binName, b)
+	}
+	return ok
+}
+
+// findVersion 查找定义 python 版本的文件，返回文件路径和版本
+func (s *specPython) findVersion() (string, string, error) {
+	names := pythonVersionFiles
+	if s.PythonVersionFile != "auto" {
+		names = []string{s.PythonVersionFile}
+	}
+	for _, name := range names {
+		fp, err := findFileUpper(name, 128)
+		if err != nil {
+			if errors.Is(err, errFileNotFound) {
+				continue
+			}
+			return "", "", err
+		}
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			return "", "", err
+		}
+		if name == ".python-version" {
+			return fp, pyenvVersions(content), nil
+		}
+		var pp struct {
+			Project struct {
+				RequiresPython string `toml:"requires-python"`
+			} `toml:"project"`
+			Tool struct {
+				Poetry struct {
+					Dependencies map[string]any `toml:"dependencies"`
+				} `toml:"poetry"`
+			} `toml:"tool"`
+		}
+		if err = xcfg.ParseBytes(".toml", content, &pp); err != nil {
+			return "", "", fmt.Errorf("parser %q: %w", fp, err)
+		}
+		poetry, _ := pp.Tool.Poetry.Dependencies["python"].(string)
+		if v := cmp.Or(pp.Project.RequiresPython, poetry); v != "" {
+			return fp, v, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %q", errFileNotFound, names)
+}
+
+func (s *specPython) installDirs() []string {
+	if len(s.InstallDirs) > 0 {
+		return expandHomeAll(s.InstallDirs)
+	}
+	root := cmp.Or(getenv("PYENV_ROOT"), filepath.Join(homeDir, ".pyenv"))
+	return []string{filepath.Join(root, "versions")}
+}
+
+// pyenvVersions 读取 .python-version 中的所有版本，以空格分隔，
+// pyenv 支持每行一个或者一行多个版本，# 开头的行为注释
+func pyenvVersions(content []byte) string {
+	var versions []string
+	for line := range strings.Lines(string(content)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		versions = append(versions, strings.Fields(line)...)
+	}
+	return strings.Join(versions, " ")
+}
@@ -0,0 +1,165 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeExec 创建测试用的可执行文件
+func writeExec(t *testing.T, fp string) {
+	writeFile(t, fp, "#!/bin/sh\n")
+	if err := os.Chmod(fp, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpecPython_Parser(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	versions := t.TempDir()
+	for _, v := range []string{"3.11.4", "3.12.1", "pypy3.10-7.3.12"} {
+		writeExec(t, filepath.Join(versions, v, "bin", "python"))
+	}
+	t.Setenv("PATH", t.TempDir())
+
+	tests := []struct {
+		name    string
+		files   map[string]string // 项目目录中的文件
+		spec    map[string]any
+		version string
+		want    string // 期望的 Cmd，相对于 versions 或者项目目录
+		wantEnv string
+		wantErr bool
+	}{
+		{
+			name: "venv first",
+			files: map[string]string{
+				".python-version":  "3.12\n",
+				".venv/pyvenv.cfg": "home = /usr/bin\n",
+				".venv/bin/python": "",
+			},
+			spec:    map[string]any{"Venv": "auto", "PythonVersionFile": "auto"},
+			want:    "project:.venv/bin/python",
+			wantEnv: "VIRTUAL_ENV=",
+		},
+		{
+			name: ".python-version",
+			files: map[string]string{
+				".python-version": "3.11\n",
+				"pyproject.toml":  "[project]\nrequires-python = \">=3.12\"\n",
+			},
+			spec: map[string]any{"Venv": "auto", "PythonVersionFile": "auto"},
+			want: "versions:3.11.4/bin/python",
+		},
+		{
+			name: "pyproject.toml",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nrequires-python = \">=3.11,<3.13\"\n",
+			},
+			spec: map[string]any{"PythonVersionFile": "auto"},
+			want: "versions:3.12.1/bin/python",
+		},
+		{
+			name: "Rule.Version over version file",
+			files: map[string]string{
+				".python-version": "3.12\n",
+			},
+			spec:    map[string]any{"PythonVersionFile": "auto"},
+			version: "3.11",
+			want:    "versions:3.11.4/bin/python",
+		},
+		{
+			name: "pyenv version by name",
+			files: map[string]string{
+				".python-version": "pypy3.10-7.3.12\n",
+			},
+			spec: map[string]any{"PythonVersionFile": "auto"},
+			want: "versions:pypy3.10-7.3.12/bin/python",
+		},
+		{
+			name: "not semver and not installed",
+			files: map[string]string{
+				".python-version": "miniconda3-latest\n",
+			},
+			spec: map[string]any{"PythonVersionFile": "auto"},
+			want: "python",
+		},
+		{
+			name: "multiple versions in one line",
+			files: map[string]string{
+				".python-version": "3.9.1 3.12.1\n",
+			},
+			spec: map[string]any{"PythonVersionFile": "auto"},
+			want: "versions:3.12.1/bin/python",
+		},
+		{
+			name: "multiple versions in lines",
+			files: map[string]string{
+				".python-version": "# comment\n3.9.1\n3.11\n",
+			},
+			spec: map[string]any{"PythonVersionFile": "auto"},
+			want: "versions:3.11.4/bin/python",
+		},
+		{
+			name: "fallback when not found",
+			files: map[string]string{
+				".python-version": "3.9\n",
+			},
+			spec: map[string]any{"PythonVersionFile": "auto"},
+			want: "python",
+		},
+		{
+			name: "strict when not found",
+			files: map[string]string{
+				".python-version": "3.9\n",
+			},
+			spec:    map[string]any{"PythonVersionFile": "auto", "Strict": true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			for name, content := range tt.files {
+				fp := filepath.Join(project, name)
+				if filepath.Base(filepath.Dir(fp)) == "bin" {
+					writeExec(t, fp)
+				} else {
+					writeFile(t, fp, content)
+				}
+			}
+			t.Chdir(project)
+
+			tt.spec["InstallDirs"] = []string{versions}
+			r := &Rule{Cmd: "python", Spec: tt.spec, Version: tt.version}
+			err := getSpecParser("python").Parser(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want := tt.want
+			if after, ok := strings.CutPrefix(want, "versions:"); ok {
+				want = filepath.Join(versions, after)
+			} else if after, ok := strings.CutPrefix(want, "project:"); ok {
+				want = filepath.Join(project, after)
+			}
+			if r.Cmd != want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, want)
+			}
+			if tt.wantEnv != "" && !slices.ContainsFunc(r.Env, func(e string) bool {
+				return strings.HasPrefix(e, tt.wantEnv)
+			}) {
+				t.Errorf("Env = %q, want %q", r.Env, tt.wantEnv)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/xanygo/anygo/xcfg"
+)
+
+// specRust cargo、rustc 等命令的 Spec
+type specRust struct {
+	specCommon
+
+	// RustToolchainFile 定义 rust 工具链的文件，支持：
+	// auto: 依次查找 rust-toolchain.toml、rust-toolchain
+	// rust-toolchain.toml、rust-toolchain: 只使用指定的文件
+	// "", no: 跳过
+	RustToolchainFile string `json:",omitempty"`
+
+	// RustupHome rustup 的目录，可选，默认为环境变量 RUSTUP_HOME 或者 ~/.rustup
+	RustupHome string `json:",omitempty"`
+
+	// binName 当前命令，如 cargo、rustc
+	binName string
+}
+
+func newSpecRust(name string) specParser {
+	return &specRust{binName: name}
+}
+
+var rustToolchainFiles = []string{"rust-toolchain.toml", "rust-toolchain"}
+
+func (s *specRust) Check() error {
+	switch s.RustToolchainFile {
+	case "", "no", "auto":
+		return nil
+	}
+	if !slices.Contains(rustToolchainFiles, s.RustToolchainFile) {
+		return fmt.Errorf("not support RustToolchainFile=%q, now support 'auto', %q", s.RustToolchainFile, rustToolchainFiles)
+	}
+	return nil
+}
+
+// rustToolchain rust-toolchain.toml 中 [toolchain] 的内容
+type rustToolchain struct {
+	Channel    string   `toml:"channel"`
+	Components []string `toml:"components"`
+	Targets    []string `toml:"targets"`
+}
+
+func (s *specRust) Parser(r *Rule) error {
+	if err := convertByJSON(r.Spec, s); err != nil {
+		return err
+	}
+	if err := s.Check(); err != nil {
+		return err
+	}
+	if tp := r.toolPick; tp != nil {
+		// 让 rustup 的代理命令(如 cargo 调用的 rustc)也使用相同的工具链
+		if tp.Bin != "" {
+			r.Env = append(r.Env, "RUSTUP_TOOLCHAIN="+tp.Version)
+			r.decide("set RUSTUP_TOOLCHAIN=%s", tp.Version)
+		}
+		return nil
+	}
+	if r.Version != "" {
+		return s.useToolchain(r, &rustToolchain{Channel: r.Version}, "Rule.Version")
+	}
+	if s.RustToolchainFile == "" || s.RustToolchainFile == "no" {
+		return nil
+	}
+	fp, tc, err := s.findToolchain()
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	r.decide("rust toolchain %+v defined in %q", *tc, fp)
+	return s.useToolchain(r, tc, fp)
+}
+
+// useToolchain 使用 tc 对应的工具链，from 为定义工具链的地方
+func (s *specRust) useToolchain(r *Rule, tc *rustToolchain, from string) error {
+	dir := s.toolchainDir(tc.Channel)
+	if dir == "" {
+		return s.missing(r, &specMissError{
+			Bin:      "rust toolchain",
+			Want:     tc.Channel,
+			From:     from,
+			Searched: []string{s.toolchainsRoot()},
+			Hint:     fmt.Sprintf("install it by 'rustup toolchain install %s', or set RustupHome", tc.Channel),
+		})
+	}
+	for _, msg := range checkRustToolchain(dir, tc) {
+		r.decide("%s", msg)
+	}
+
+	binDir := filepath.Join(dir, "bin")
+	if bp := filepath.Join(binDir, s.binName); isExecutable(bp) {
+		r.Cmd = bp
+	}
+	// cargo 会通过 PATH 调用 rustc 等命令，RUSTUP_TOOLCHAIN 让 rustup 的代理命令也使用相同的工具链
+	r.Env = prependPathEnv(r.Env, binDir)
+	r.Env = append(r.Env, "RUSTUP_TOOLCHAIN="+filepath.Base(dir))
+	r.decide("using rust toolchain %q, Cmd = %q", dir, r.Cmd)
+	return nil
+}
+
+// findToolchain 查找定义 rust 工具链的文件，返回文件路径和其内容
+func (s *specRust) findToolchain() (string, *rustToolchain, error) {
+	names := rustToolchainFiles
+	if s.RustToolchainFile != "auto" {
+		names = []string{s.RustToolchainFile}
+	}
+	for _, name := range names {
+		fp, err := findFileUpper(name, 128)
+		if err != nil {
+			if errors.Is(err, errFileNotFound) {
+				continue
+			}
+			return "", nil, err
+		}
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			return "", nil, err
+		}
+		tc, err := parseRustToolchain(content)
+		if err != nil {
+			return "", nil, fmt.Errorf("parser %q: %w", fp, err)
+		}
+		return fp, tc, nil
+	}
+	return "", nil, fmt.Errorf("%w: %q", errFileNotFound, names)
+}
+
+// parseRustToolchain 解析 rust-toolchain.toml，或者只有一行 channel 的旧格式的 rust-toolchain 文件
+func parseRustToolchain(content []byte) (*rustToolchain, error) {
+	if !bytes.Contains(content, []byte("[toolchain]")) {
+		channel := firstLine(content)
+		if channel == "" {
+			return nil, errors.New("channel is empty")
+		}
+		return &rustToolchain{Channel: channel}, nil
+	}
+	var f struct {
+		Toolchain rustToolchain `toml:"toolchain"`
+	}
+	if err := xcfg.ParseBytes(".toml", content, &f); err != nil {
+		return nil, err
+	}
+	if f.Toolchain.Channel == "" {
+		return nil, errors.New("toolchain.channel is empty")
+	}
+	return &f.Toolchain, nil
+}
+
+func (s *specRust) toolchainsRoot() string {
+	home := expandHome(s.RustupHome)
+	if home == "" {
+		home = cmp.Or(getenv("RUSTUP_HOME"), filepath.Join(homeDir, ".rustup"))
+	}
+	return filepath.Join(home, "toolchains")
+}
+
+// toolchainDir 查找 channel 对应的工具链目录，如 ~/.rustup/toolchains/1.75.0-x86_64-unknown-linux-gnu
+// channel 为版本号时(如 1.75)，使用已安装的最高的 1.75.x
+func (s *specRust) toolchainDir(channel string) string {
+	root := s.toolchainsRoot()
+	watchPath(root)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return ""
+	}
+	host := rustHostTriple()
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || e.Type()&os.ModeSymlink != 0 {
+			names = append(names, e.Name())
+		}
+	}
+	// channel 中已经包含了 host，如 stable-x86_64-unknown-linux-gnu
+	if slices.Contains(names, channel) {
+		return filepath.Join(root, channel)
+	}
+	if slices.Contains(names, channel+"-"+host) {
+		return filepath.Join(root, channel+"-"+host)
+	}
+
+	vc, err := parseConstraint(channel)
+	if err != nil {
+		return ""
+	}
+	var best string
+	var bestVersion semVersion
+	for _, name := range names {
+		vs, ok := strings.CutSuffix(name, "-"+host)
+		if !ok {
+			continue
+		}
+		v, ok := parseVersion(vs)
+		if !ok || !vc.Check(v) {
+			continue
+		}
+		if best == "" || v.Compare(bestVersion) > 0 {
+			best, bestVersion = name, v
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return filepath.Join(root, best)
+}
+
+// rustHostTriple 当前系统的 rust host triple，如 x86_64-unknown-linux-gnu
+func rustHostTriple() string {
+	arch := map[string]string{
+		"amd64":   "x86_64",
+		"arm64":   "aarch64",
+		"386":     "i686",
+		"riscv64": "riscv64gc",
+	}[runtime.GOARCH]
+	if arch == "" {
+		arch = runtime.GOARCH
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return arch + "-apple-darwin"
+	case "windows":
+		return arch + "-pc-windows-msvc"
+	default:
+		return arch + "-unknown-" + runtime.GOOS + "-gnu"
+	}
+}
+
+// checkRustToolchain 检查工具链是否安装了 rust-toolchain.toml 中要求的 components 和 targets，返回缺失的信息
+func checkRustToolchain(dir string, tc *rustToolchain) []string {
+	var msgs []string
+	// rustup component add、rustup target add 会修改此目录
+	watchPath(filepath.Join(dir, "lib", "rustlib"))
+	if len(tc.Components) > 0 {
+		installed := map[string]bool{}
+		if f, err := os.Open(filepath.Join(dir, "lib", "rustlib", "components")); err == nil {
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				name := strings.TrimSpace(sc.Text())
+				installed[name] = true
+				installed[strings.TrimSuffix(name, "-preview")] = true
+			}
+			_ = f.Close()
+		}
+		for _, c := range tc.Components {
+			if !installed[c] && !installed[c+"-"+rustHostTriple()] {
+				msgs = append(msgs, fmt.Sprintf("rust component %q not installed in %q", c, dir))
+			}
+		}
+	}
+	for _, t := range tc.Targets {
+		if _, err := os.Stat(filepath.Join(dir, "lib", "rustlib", t)); err != nil {
+			msgs = append(msgs, fmt.Sprintf("rust target %q not installed in %q", t, dir))
+		}
+	}
+	return msgs
+}
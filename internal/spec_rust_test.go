@@ -0,0 +1,122 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRustToolchain(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *rustToolchain
+		wantErr bool
+	}{
+		{
+			name:    "rust-toolchain channel",
+			content: "stable\n",
+			want:    &rustToolchain{Channel: "stable"},
+		},
+		{
+			name:    "rust-toolchain version",
+			content: "1.75.0\n",
+			want:    &rustToolchain{Channel: "1.75.0"},
+		},
+		{
+			name:    "rust-toolchain.toml",
+			content: "[toolchain]\nchannel = \"1.75\"\ncomponents = [\"rustfmt\", \"clippy\"]\ntargets = [\"wasm32-unknown-unknown\"]\n",
+			want: &rustToolchain{
+				Channel:    "1.75",
+				Components: []string{"rustfmt", "clippy"},
+				Targets:    []string{"wasm32-unknown-unknown"},
+			},
+		},
+		{
+			name:    "empty",
+			content: "\n",
+			wantErr: true,
+		},
+		{
+			name:    "toml without channel",
+			content: "[toolchain]\ncomponents = [\"rustfmt\"]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRustToolchain([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRustToolchain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRustToolchain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecRust_toolchainDir(t *testing.T) {
+	home := t.TempDir()
+	host := rustHostTriple()
+	for _, name := range []string{"stable-" + host, "nightly-2024-01-01-" + host, "1.74.1-" + host, "1.75.0-" + host, "1.75.2-" + host} {
+		if err := os.MkdirAll(filepath.Join(home, "toolchains", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &specRust{RustupHome: home}
+	tests := []struct {
+		channel string
+		want    string
+	}{
+		{channel: "stable", want: "stable-" + host},
+		{channel: "stable-" + host, want: "stable-" + host},
+		{channel: "nightly-2024-01-01", want: "nightly-2024-01-01-" + host},
+		// 具体的版本
+		{channel: "1.75.0", want: "1.75.0-" + host},
+		// 版本号不完整时，使用已安装的最高的
+		{channel: "1.75", want: "1.75.2-" + host},
+		{channel: "1.76"},
+		{channel: "beta"},
+	}
+	for _, tt := range tests {
+		var want string
+		if tt.want != "" {
+			want = filepath.Join(home, "toolchains", tt.want)
+		}
+		if got := s.toolchainDir(tt.channel); got != want {
+			t.Errorf("toolchainDir(%q) = %q, want %q", tt.channel, got, want)
+		}
+	}
+}
+
+func TestSpecRust_findToolchain(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rust-toolchain"), "1.74\n")
+	writeFile(t, filepath.Join(dir, "rust-toolchain.toml"), "[toolchain]\nchannel = \"1.75\"\n")
+	t.Chdir(dir)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		// rust-toolchain.toml 优先
+		{file: "auto", want: "1.75"},
+		{file: "rust-toolchain", want: "1.74"},
+		{file: "rust-toolchain.toml", want: "1.75"},
+	}
+	for _, tt := range tests {
+		s := &specRust{RustToolchainFile: tt.file}
+		_, tc, err := s.findToolchain()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.Channel != tt.want {
+			t.Errorf("findToolchain(%q) = %q, want %q", tt.file, tc.Channel, tt.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// goSDKs 测试用的 go：PATH 中的 go1.21.3、go1.23.0，GoSDKDir 中的 go1.22.1、go1.22.5
+type goSDKs struct {
+	pathDir string
+	sdkDir  string
+}
+
+func newGoSDKs(t *testing.T) *goSDKs {
+	gs := &goSDKs{pathDir: t.TempDir(), sdkDir: t.TempDir()}
+	for _, name := range []string{"go1.21.3", "go1.23.0"} {
+		writeExec(t, filepath.Join(gs.pathDir, name))
+	}
+	for _, name := range []string{"go1.22.1", "go1.22.5"} {
+		writeExec(t, filepath.Join(gs.sdkDir, name, "bin", "go"))
+	}
+	t.Setenv("PATH", gs.pathDir)
+	t.Setenv("GOTOOLCHAIN", "")
+	return gs
+}
+
+// path 将 "path:go1.21.3"、"sdk:go1.22.5" 转换为完整的路径，其他的保持不变
+func (gs *goSDKs) path(name string) string {
+	if after, ok := strings.CutPrefix(name, "path:"); ok {
+		return filepath.Join(gs.pathDir, after)
+	}
+	if after, ok := strings.CutPrefix(name, "sdk:"); ok {
+		return filepath.Join(gs.sdkDir, after, "bin", "go")
+	}
+	return name
+}
+
+func TestSpecGo_goVersionFile(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	gs := newGoSDKs(t)
+	tests := []struct {
+		name        string
+		goMod       string
+		toolchain   string // 环境变量 GOTOOLCHAIN
+		strict      bool
+		want        string
+		wantPinned  bool // 是否设置了 GOTOOLCHAIN=local
+		wantDecided string
+	}{
+		{
+			name:       "go directive uses newest patch",
+			goMod:      "go 1.22\n",
+			want:       "sdk:go1.22.5",
+			wantPinned: true,
+		},
+		{
+			name:       "toolchain directive",
+			goMod:      "go 1.22\ntoolchain go1.23.0\n",
+			want:       "path:go1.23.0",
+			wantPinned: true,
+		},
+		{
+			name:       "toolchain directive not found",
+			goMod:      "go 1.22\ntoolchain go1.22.9\n",
+			want:       "sdk:go1.22.5",
+			wantPinned: true,
+		},
+		{
+			name:        "toolchain default",
+			goMod:       "go 1.22\ntoolchain default\n",
+			strict:      true,
+			want:        "sdk:go1.22.5",
+			wantPinned:  true,
+			wantDecided: "'toolchain default'",
+		},
+		{
+			name:        "GOTOOLCHAIN=local",
+			goMod:       "go 1.22\ntoolchain go1.23.0\n",
+			toolchain:   "local",
+			want:        "go",
+			wantDecided: "GOTOOLCHAIN=local",
+		},
+		{
+			name:      "GOTOOLCHAIN=goX",
+			goMod:     "go 1.22\ntoolchain go1.23.0\n",
+			toolchain: "go1.21.3",
+			want:      "path:go1.21.3",
+		},
+		{
+			name:       "GOTOOLCHAIN=goX not found",
+			goMod:      "go 1.22\n",
+			toolchain:  "go1.22.9+auto",
+			want:       "sdk:go1.22.5",
+			wantPinned: false, // 只在 GOTOOLCHAIN 为空、auto、path 时设置
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/demo\n\n"+tt.goMod)
+			t.Chdir(dir)
+
+			r := &Rule{Cmd: "go"}
+			if tt.toolchain != "" {
+				r.Env = []string{"GOTOOLCHAIN=" + tt.toolchain}
+			}
+			s := &specGo{GoVersionFile: "go.mod", GoSDKDir: gs.sdkDir}
+			s.Strict = tt.strict
+			if err := s.goVersionFile(r); err != nil {
+				t.Fatal(err)
+			}
+			if want := gs.path(tt.want); r.Cmd != want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, want)
+			}
+			pinned := lookupEnv(r.Env, "GOTOOLCHAIN") == "local" && tt.toolchain != "local"
+			if pinned != tt.wantPinned {
+				t.Errorf("Env = %q, want pinned %v", r.Env, tt.wantPinned)
+			}
+			if tt.wantDecided != "" && !strings.Contains(strings.Join(r.decisions, "\n"), tt.wantDecided) {
+				t.Errorf("decisions = %q, want %q", r.decisions, tt.wantDecided)
+			}
+		})
+	}
+}
+
+func TestSpecCommon_missing(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	gs := newGoSDKs(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/demo\n\ngo 1.20\n")
+	t.Chdir(dir)
+
+	tests := []struct {
+		name       string
+		spec       map[string]any
+		ruleStrict bool
+		wantErr    bool
+	}{
+		{name: "fallback", spec: map[string]any{}},
+		{name: "Spec.Strict", spec: map[string]any{"Strict": true}, wantErr: true},
+		{name: "Rule.Strict", spec: map[string]any{}, ruleStrict: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec["GoVersionFile"] = "go.mod"
+			tt.spec["GoSDKDir"] = gs.sdkDir
+			r := &Rule{Cmd: "go", Spec: tt.spec, Strict: tt.ruleStrict}
+			err := r.BeforeExec(context.Background(), "go")
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Cmd != "go" {
+					t.Errorf("Cmd = %q, want the default", r.Cmd)
+				}
+				if !strings.Contains(strings.Join(r.decisions, "\n"), "not found") {
+					t.Errorf("decisions = %q, want the missing reason", r.decisions)
+				}
+				return
+			}
+			var me *specMissError
+			if !errors.As(err, &me) {
+				t.Fatalf("BeforeExec() error = %v, want *specMissError", err)
+			}
+			if me.Want != "go1.20" || filepath.Base(me.From) != "go.mod" || me.Hint == "" ||
+				!slices.Contains(me.Searched, gs.sdkDir) {
+				t.Errorf("specMissError = %+v", me)
+			}
+		})
+	}
+}
+
+func TestSpecGo_dryRun(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	gs := newGoSDKs(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/demo\n\ngo 1.20\n")
+	t.Chdir(dir)
+
+	r := &Rule{
+		Cmd:    "go",
+		Spec:   map[string]any{"GoVersionFile": "go.mod", "GoSDKDir": gs.sdkDir, "GoInstall": "auto"},
+		dryRun: true,
+	}
+	if err := r.BeforeExec(context.Background(), "go"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Cmd != "go" {
+		t.Errorf("Cmd = %q, want the default", r.Cmd)
+	}
+	if !strings.Contains(strings.Join(r.decisions, "\n"), "would install go1.20") {
+		t.Errorf("decisions = %q, want the skipped install", r.decisions)
+	}
+}
+
+func TestSpecGo_pinVersion(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	gs := newGoSDKs(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/demo\n\ngo 1.22\ntoolchain go1.23.0\n")
+	t.Chdir(dir)
+
+	tests := []struct {
+		name       string
+		version    string
+		toolchain  string // 环境变量 GOTOOLCHAIN
+		want       string
+		wantPinned bool
+	}{
+		{name: "minor", version: "1.21", want: "path:go1.21.3", wantPinned: true},
+		{name: "newest patch", version: "go1.22", want: "sdk:go1.22.5", wantPinned: true},
+		{name: "range", version: ">=1.22.2 <1.23", want: "sdk:go1.22.5", wantPinned: true},
+		{name: "GOTOOLCHAIN=auto", version: "1.21", toolchain: "auto", want: "path:go1.21.3", wantPinned: true},
+		{name: "GOTOOLCHAIN=go1.23.0", version: "1.21", toolchain: "go1.23.0", want: "path:go1.21.3"},
+		{name: "not found", version: "1.20", want: "go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Rule{
+				Cmd:     "go",
+				Version: tt.version,
+				Spec:    map[string]any{"GoVersionFile": "go.mod", "GoSDKDir": gs.sdkDir},
+			}
+			if tt.toolchain != "" {
+				r.Env = []string{"GOTOOLCHAIN=" + tt.toolchain}
+			}
+			if err := getSpecParser("go").Parser(r); err != nil {
+				t.Fatal(err)
+			}
+			// Version 优先于 go.mod 中的 toolchain go1.23.0
+			if want := gs.path(tt.want); r.Cmd != want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, want)
+			}
+			if pinned := lookupEnv(r.Env, "GOTOOLCHAIN") == "local"; pinned != tt.wantPinned {
+				t.Errorf("Env = %q, want pinned %v", r.Env, tt.wantPinned)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// asdfToolNames 命令对应的 asdf、mise 中的工具名称，未列出的使用命令名称
+var asdfToolNames = map[string][]string{
+	"node": {"nodejs", "node"},
+	"npm":  {"nodejs", "node"},
+	"npx":  {"nodejs", "node"},
+
+	"go": {"golang", "go"},
+
+	"python3": {"python"},
+	"pip":     {"python"},
+	"pip3":    {"python"},
+	"pytest":  {"python"},
+
+	"javac":   {"java"},
+	"jar":     {"java"},
+	"jshell":  {"java"},
+	"javadoc": {"java"},
+	"mvn":     {"maven"},
+
+	"cargo":        {"rust"},
+	"rustc":        {"rust"},
+	"rustdoc":      {"rust"},
+	"rustfmt":      {"rust"},
+	"cargo-clippy": {"rust"},
+}
+
+// toolVersion .tool-versions 中的一个工具
+type toolVersion struct {
+	File     string   // 所在的文件
+	Name     string   // 工具名称，如 nodejs
+	Versions []string // 版本，可以有多个，依次使用
+}
+
+// toolPick .tool-versions 为当前命令选择的版本，代替各 Spec 中根据版本文件选择版本的步骤，
+// 各 Spec 的其他逻辑，如 go 的 GoWork、java 的 JAVA_HOME，仍然执行
+type toolPick struct {
+	File    string // 定义版本的 .tool-versions 文件
+	Version string // 使用的版本，如 1.21.5、system
+	Bin     string // 使用的命令，版本为 system 时为空
+}
+
+// toolVersions 使用 .tool-versions 中定义的版本，找到时设置 r.toolPick
+func (s *specCommon) toolVersions(r *Rule, binName string) error {
+	if s.ToolVersions == "" || s.ToolVersions == "no" {
+		return nil
+	}
+	if r.Version != "" {
+		r.decide("Version=%q is set, skip ToolVersions", r.Version)
+		return nil
+	}
+	names := s.toolNames(binName)
+	tv, err := findToolVersion(names)
+	if err != nil || tv == nil {
+		return err
+	}
+	r.decide("%s versions %q defined in %q", tv.Name, tv.Versions, tv.File)
+
+	var searched []string
+	for _, version := range tv.Versions {
+		if version == "system" {
+			r.decide("%s version is system, using %q", tv.Name, r.Cmd)
+			r.toolPick = &toolPick{File: tv.File, Version: version}
+			return nil
+		}
+		if dir, ok := strings.CutPrefix(version, "path:"); ok {
+			searched = append(searched, dir)
+			if bp := findToolBin(expandHome(dir), binName); bp != "" {
+				s.useToolBin(r, tv, version, bp)
+				return nil
+			}
+			continue
+		}
+		// 版本号会用于拼接路径和命令名，如 "../../x" 会指向安装目录之外
+		if err = checkCmdVersion(version); err != nil {
+			return fmt.Errorf("%s: %w", tv.File, err)
+		}
+		for _, root := range s.toolRoots() {
+			for _, name := range names {
+				dir := filepath.Join(root, name)
+				searched = append(searched, dir)
+				if bp := findToolInstalled(dir, version, binName, r.Trace); bp != "" {
+					s.useToolBin(r, tv, version, bp)
+					return nil
+				}
+			}
+		}
+		// 如 go1.21.5、terraform1.5.7
+		searched = append(searched, "$PATH")
+		if bp, err := exec.LookPath(binName + version); err == nil {
+			s.useToolBin(r, tv, version, bp)
+			return nil
+		}
+	}
+	return s.missing(r, &specMissError{
+		Bin:      binName,
+		Want:     strings.Join(tv.Versions, " "),
+		From:     tv.File,
+		Searched: slices.Compact(searched),
+		Hint:     fmt.Sprintf("install it by 'asdf install %s %s' or 'mise install', or set ToolRoots", tv.Name, tv.Versions[0]),
+	})
+}
+
+func (s *specCommon) useToolBin(r *Rule, tv *toolVersion, version string, bp string) {
+	r.Cmd = bp
+	// 如 npm 会通过 PATH 调用 node，需要使用同一个版本
+	r.Env = prependPathEnv(r.Env, filepath.Dir(bp))
+	r.toolPick = &toolPick{File: tv.File, Version: version, Bin: bp}
+	r.decide("using %q", bp)
+}
+
+func (s *specCommon) toolNames(binName string) []string {
+	if s.ToolName != "" {
+		return []string{s.ToolName}
+	}
+	if names, ok := asdfToolNames[binName]; ok {
+		return names
+	}
+	return []string{binName}
+}
+
+func (s *specCommon) toolRoots() []string {
+	if len(s.ToolRoots) > 0 {
+		return expandHomeAll(s.ToolRoots)
+	}
+	asdf := cmp.Or(getenv("ASDF_DATA_DIR"), filepath.Join(homeDir, ".asdf"))
+	mise := cmp.Or(getenv("MISE_DATA_DIR"), filepath.Join(homeDir, ".local", "share", "mise"))
+	return []string{filepath.Join(asdf, "installs"), filepath.Join(mise, "installs")}
+}
+
+// findToolVersion 从当前目录开始向上查找 .tool-versions，返回最近的定义了工具 names 中任意一个的
+// 和 asdf 一样，最后查找 ~/.tool-versions
+func findToolVersion(names []string) (*toolVersion, error) {
+	files, err := findFilesUpper(".tool-versions", 128)
+	if err != nil {
+		return nil, err
+	}
+	if fp := filepath.Join(homeDir, ".tool-versions"); !slices.Contains(files, fp) {
+		files = append(files, fp)
+	}
+	for _, fp := range files {
+		watchPath(fp)
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if tv := parseToolVersions(content, names); tv != nil {
+			tv.File = fp
+			return tv, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseToolVersions 解析 .tool-versions 的内容，返回 names 中第一个找到的工具
+// 每行的格式为 "{tool} {version} [{version}...]"，"#" 之后为注释
+func parseToolVersions(content []byte, names []string) *toolVersion {
+	tools := map[string][]string{}
+	for line := range strings.Lines(string(content)) {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if _, ok := tools[fields[0]]; !ok {
+			tools[fields[0]] = fields[1:]
+		}
+	}
+	for _, name := range names {
+		if versions, ok := tools[name]; ok {
+			return &toolVersion{Name: name, Versions: versions}
+		}
+	}
+	return nil
+}
+
+// findToolInstalled 在工具的安装目录 dir 中查找 version 版本的命令 binName，
+// version 和目录名不完全一致时，使用满足版本要求的最高版本，如 "20" 可以使用 20.11.0
+func findToolInstalled(dir string, version string, binName string, trace bool) string {
+	watchPath(dir)
+	if _, err := os.Stat(dir); err != nil {
+		return ""
+	}
+	if bp := findToolBin(filepath.Join(dir, version), binName); bp != "" {
+		return bp
+	}
+	vc, err := parseConstraint(version)
+	if err != nil {
+		return ""
+	}
+	vr := &versionResolver{
+		InstallDirs: []string{dir},
+		Rels:        toolBinRels(binName),
+	}
+	b, ok := vr.Resolve(vc, trace)
+	if !ok {
+		return ""
+	}
+	return b.Path
+}
+
+// findToolBin 查找一个版本的安装目录 home 中的命令 binName
+func findToolBin(home string, binName string) string {
+	for _, rel := range toolBinRels(binName) {
+		if bp := filepath.Join(home, rel); isExecutable(bp) {
+			return bp
+		}
+	}
+	return ""
+}
+
+// toolBinRels 命令在安装目录中的位置，asdf 的 golang 在 go/bin 中
+func toolBinRels(binName string) []string {
+	return []string{
+		filepath.Join("bin", binName),
+		filepath.Join("go", "bin", binName),
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseToolVersions(t *testing.T) {
+	content := []byte(`# tools
+nodejs 20.11.0 18.19.0 # fallback to 18
+golang   1.21.5
+python
+terraform ref:v1.5.0
+`)
+	tests := []struct {
+		names []string
+		want  *toolVersion
+	}{
+		{
+			names: []string{"nodejs", "node"},
+			want:  &toolVersion{Name: "nodejs", Versions: []string{"20.11.0", "18.19.0"}},
+		},
+		{
+			names: []string{"go", "golang"},
+			want:  &toolVersion{Name: "golang", Versions: []string{"1.21.5"}},
+		},
+		{
+			names: []string{"python"},
+		},
+		{
+			names: []string{"helm"},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseToolVersions(content, tt.names); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseToolVersions(%q) = %+v, want %+v", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestParserSpecial_toolVersionsPrecedence(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	setHomeDir(t, t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+	toolRoot := t.TempDir()
+	writeExec(t, filepath.Join(toolRoot, "nodejs", "20.11.0", "bin", "node"))
+	nodeDir := t.TempDir()
+	writeExec(t, filepath.Join(nodeDir, "v18.19.0", "bin", "node"))
+
+	tests := []struct {
+		name         string
+		toolVersions string
+		want         string
+		wantDecided  string
+	}{
+		{
+			name:         ".tool-versions wins",
+			toolVersions: "nodejs 20.11.0\n",
+			want:         filepath.Join(toolRoot, "nodejs", "20.11.0", "bin", "node"),
+			wantDecided:  "skip the version files of the node spec",
+		},
+		{
+			name:         "not installed, use .nvmrc",
+			toolVersions: "nodejs 21.0.0\n",
+			want:         filepath.Join(nodeDir, "v18.19.0", "bin", "node"),
+		},
+		{
+			name:         "no tool, use .nvmrc",
+			toolVersions: "golang 1.21.5\n",
+			want:         filepath.Join(nodeDir, "v18.19.0", "bin", "node"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".tool-versions"), tt.toolVersions)
+			writeFile(t, filepath.Join(dir, ".nvmrc"), "18\n")
+			t.Chdir(dir)
+
+			r := &Rule{
+				Cmd: "node",
+				Spec: map[string]any{
+					"ToolVersions":    "auto",
+					"ToolRoots":       []string{toolRoot},
+					"NodeVersionFile": "auto",
+					"InstallDirs":     []string{nodeDir},
+				},
+			}
+			if err := parserSpecial("node", r); err != nil {
+				t.Fatal(err)
+			}
+			if r.Cmd != tt.want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, tt.want)
+			}
+			if tt.wantDecided != "" && !strings.Contains(strings.Join(r.decisions, "\n"), tt.wantDecided) {
+				t.Errorf("decisions = %q, want %q", r.decisions, tt.wantDecided)
+			}
+		})
+	}
+}
+
+// .tool-versions 只代替选择版本的步骤，各 Spec 的环境变量等仍然生效
+func TestParserSpecial_toolVersionsEnv(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	setHomeDir(t, t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("GOTOOLCHAIN", "")
+	toolRoot := t.TempDir()
+	goBin := filepath.Join(toolRoot, "golang", "1.21.5", "go", "bin", "go")
+	writeExec(t, goBin)
+	javaHome := filepath.Join(toolRoot, "java", "temurin-17.0.9")
+	writeExec(t, filepath.Join(javaHome, "bin", "java"))
+
+	tests := []struct {
+		name         string
+		bin          string
+		toolVersions string
+		spec         map[string]any
+		want         string
+		wantEnv      []string
+	}{
+		{
+			name:         "go with GoWork",
+			bin:          "go",
+			toolVersions: "golang 1.21.5\n",
+			spec:         map[string]any{"GoWork": "auto", "GoVersionFile": "go.mod"},
+			want:         goBin,
+			wantEnv:      []string{"GOWORK=off", "GOTOOLCHAIN=local"},
+		},
+		{
+			name:         "java with JAVA_HOME",
+			bin:          "java",
+			toolVersions: "java temurin-17.0.9\n",
+			spec:         map[string]any{"JavaVersionFile": "auto"},
+			want:         filepath.Join(javaHome, "bin", "java"),
+			wantEnv:      []string{"JAVA_HOME=" + javaHome},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".tool-versions"), tt.toolVersions)
+			// 当前目录不在 go.work 中，go.mod 中的版本不再使用
+			writeFile(t, filepath.Join(dir, "go.work"), "go 1.21\n\nuse ./other\n")
+			writeFile(t, filepath.Join(dir, "go.mod"), "module x\n\ngo 1.22.0\n")
+			t.Chdir(dir)
+
+			spec := map[string]any{
+				"ToolVersions": "auto",
+				"ToolRoots":    []string{toolRoot},
+			}
+			maps.Copy(spec, tt.spec)
+			r := &Rule{Cmd: tt.bin, Spec: spec}
+			if err := parserSpecial(tt.bin, r); err != nil {
+				t.Fatal(err)
+			}
+			if r.Cmd != tt.want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, tt.want)
+			}
+			for _, kv := range tt.wantEnv {
+				if !slices.Contains(r.Env, kv) {
+					t.Errorf("Env = %q, want %q", r.Env, kv)
+				}
+			}
+		})
+	}
+}
+
+func TestParserSpecial_toolVersionsInvalid(t *testing.T) {
+	setHomeDir(t, t.TempDir())
+	toolRoot := t.TempDir()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".tool-versions"), "nodejs ../../x\n")
+	t.Chdir(dir)
+
+	r := &Rule{
+		Cmd: "node",
+		Spec: map[string]any{
+			"ToolVersions": "auto",
+			"ToolRoots":    []string{toolRoot},
+		},
+	}
+	err := parserSpecial("node", r)
+	if err == nil || !strings.Contains(err.Error(), "invalid version") {
+		t.Fatalf("parserSpecial() = %v, want invalid version error", err)
+	}
+	if r.Cmd != "node" {
+		t.Errorf("Cmd = %q, want unchanged", r.Cmd)
+	}
+}
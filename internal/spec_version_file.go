@@ -0,0 +1,231 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/xcfg"
+)
+
+// specVersionFile 通用的 Spec，用于没有专门 Spec 的命令，如 terraform、kubectl、helm、protoc
+// 从版本文件中读取版本号，并使用对应版本的命令
+type specVersionFile struct {
+	specCommon
+
+	// VersionFile 定义版本的文件名，在当前目录以及上级目录查找，如 .terraform-version
+	// "": 跳过
+	VersionFile string `json:",omitempty"`
+
+	// VersionRegexp 可选，从文件内容中提取版本号的正则，使用第一个分组，如 `helm\s+(\S+)`
+	// 和 VersionPath 都未设置时，使用文件中第一个非空、非注释的行
+	VersionRegexp string `json:",omitempty"`
+
+	// VersionPath 可选，TOML、JSON、YAML 文件中版本号的路径，使用 "." 分隔，如 "tools.protoc.version"
+	// 按照文件的后缀(.toml、.json、.yml、.yaml)解析文件
+	VersionPath string `json:",omitempty"`
+
+	// VersionCmd 命令模板，{version} 会被替换为版本号，如 "terraform{version}"、"~/.tfenv/versions/{version}/terraform"
+	// 不包含路径时，在 PATH 中查找
+	VersionCmd string `json:",omitempty"`
+
+	// binName 当前命令，如 terraform
+	binName string
+}
+
+func newSpecVersionFile(name string) specParser {
+	return &specVersionFile{binName: name}
+}
+
+var versionPathExts = []string{".toml", ".json", ".yml", ".yaml"}
+
+func (s *specVersionFile) Check() error {
+	if s.VersionCmd != "" && !strings.Contains(s.VersionCmd, "{version}") {
+		return fmt.Errorf("VersionCmd %q should contain {version}", s.VersionCmd)
+	}
+	if s.VersionFile == "" {
+		return nil
+	}
+	if s.VersionCmd == "" {
+		return errors.New("VersionCmd is required when VersionFile is set")
+	}
+	if s.VersionRegexp != "" && s.VersionPath != "" {
+		return errors.New("VersionRegexp and VersionPath cannot be set at the same time")
+	}
+	if s.VersionRegexp != "" {
+		reg, err := regexp.Compile(s.VersionRegexp)
+		if err != nil {
+			return fmt.Errorf("invalid VersionRegexp: %w", err)
+		}
+		if reg.NumSubexp() < 1 {
+			return fmt.Errorf("VersionRegexp %q should have a group", s.VersionRegexp)
+		}
+	}
+	if s.VersionPath != "" && !slices.Contains(versionPathExts, filepath.Ext(s.VersionFile)) {
+		return fmt.Errorf("VersionPath requires the VersionFile is one of %q", versionPathExts)
+	}
+	return nil
+}
+
+func (s *specVersionFile) Parser(r *Rule) error {
+	if err := convertByJSON(r.Spec, s); err != nil {
+		return err
+	}
+	if err := s.Check(); err != nil {
+		return err
+	}
+	if r.toolPick != nil {
+		return nil
+	}
+	if r.Version != "" {
+		return s.useVersion(r, r.Version, "Rule.Version")
+	}
+	if s.VersionFile == "" {
+		return nil
+	}
+	fp, err := findFileUpper(s.VersionFile, 128)
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	content, err := os.ReadFile(fp)
+	if err != nil {
+		return err
+	}
+	version, err := s.findVersion(content)
+	if err != nil {
+		return fmt.Errorf("parser %q: %w", fp, err)
+	}
+	r.decide("%s version %q defined in %q", s.binName, version, fp)
+	return s.useVersion(r, version, fp)
+}
+
+// useVersion 使用 version 版本的命令，from 为定义版本的地方
+// 未配置 VersionCmd 时，在 PATH 中查找 {name}{version}，version 可以为版本范围，如 "1.5" 可以使用 terraform1.5.7
+func (s *specVersionFile) useVersion(r *Rule, version string, from string) error {
+	if s.VersionCmd == "" {
+		vc, err := parseConstraint(version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", from, err)
+		}
+		vr := &versionResolver{PathPrefix: s.binName}
+		if b, ok := vr.Resolve(vc, r.Trace); ok {
+			useVersionedBin(r, s.binName, b)
+			return nil
+		}
+		return s.missing(r, &specMissError{
+			Bin:      s.binName,
+			Want:     version,
+			From:     from,
+			Searched: []string{"$PATH"},
+			Hint:     fmt.Sprintf("install it as '%s{version}' in PATH, or set VersionCmd", s.binName),
+		})
+	}
+
+	if err := checkCmdVersion(version); err != nil {
+		return fmt.Errorf("%s: %w", from, err)
+	}
+	cmd := expandHome(strings.ReplaceAll(s.VersionCmd, "{version}", version))
+	if strings.ContainsAny(cmd, `/\`) {
+		if isExecutable(cmd) {
+			r.Cmd = cmd
+			r.decide("using %q", cmd)
+			return nil
+		}
+	} else if bp, err := exec.LookPath(cmd); err == nil {
+		r.Cmd = bp
+		r.decide("using %q", bp)
+		return nil
+	}
+	searched := "$PATH"
+	if filepath.IsAbs(cmd) {
+		searched = cmd
+	}
+	return s.missing(r, &specMissError{
+		Bin:      s.binName,
+		Want:     version,
+		From:     from,
+		Searched: []string{searched},
+		Hint:     fmt.Sprintf("install it as %q", cmd),
+	})
+}
+
+// cmdVersionReg 可以替换到 VersionCmd 中的版本号
+var cmdVersionReg = regexp.MustCompile(`^[0-9A-Za-z._+-]+$`)
+
+// checkCmdVersion 检查版本号，避免版本文件中的内容，如 "../../bin/sh"，使 VersionCmd 指向其他的命令
+func checkCmdVersion(version string) error {
+	if !cmdVersionReg.MatchString(version) || strings.Contains(version, "..") {
+		return fmt.Errorf("invalid version %q, should match %s and not contain \"..\"", version, cmdVersionReg)
+	}
+	return nil
+}
+
+// findVersion 从文件内容中读取版本号
+func (s *specVersionFile) findVersion(content []byte) (string, error) {
+	var version string
+	switch {
+	case s.VersionRegexp != "":
+		m := regexp.MustCompile(s.VersionRegexp).FindSubmatch(content)
+		if m == nil {
+			return "", fmt.Errorf("VersionRegexp %q not matched", s.VersionRegexp)
+		}
+		version = string(m[1])
+	case s.VersionPath != "":
+		var data any
+		if err := xcfg.ParseBytes(filepath.Ext(s.VersionFile), content, &data); err != nil {
+			return "", err
+		}
+		v, ok := valueByPath(data, s.VersionPath)
+		if !ok {
+			return "", fmt.Errorf("VersionPath %q not found", s.VersionPath)
+		}
+		version = fmt.Sprint(v)
+	default:
+		version = firstLine(content)
+	}
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return "", errors.New("version is empty")
+	}
+	return version, nil
+}
+
+// valueByPath 读取 data 中 path 对应的值，path 使用 "." 分隔，数组使用下标，如 "tools.0.version"
+func valueByPath(data any, path string) (any, bool) {
+	cur := data
+	for _, key := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			val, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			cur = val
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			cur = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	switch cur.(type) {
+	case map[string]any, []any, nil:
+		return nil, false
+	}
+	return cur, true
+}
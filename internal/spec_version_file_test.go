@@ -0,0 +1,108 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSpecVersionFile_findVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *specVersionFile
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "first line",
+			spec:    &specVersionFile{VersionFile: ".terraform-version"},
+			content: "# comment\n\n 1.5.7 \n",
+			want:    "1.5.7",
+		},
+		{
+			name:    "regexp",
+			spec:    &specVersionFile{VersionFile: ".tool-versions", VersionRegexp: `(?m)^helm\s+(\S+)`},
+			content: "nodejs 18.0.0\nhelm 3.12.0\n",
+			want:    "3.12.0",
+		},
+		{
+			name:    "regexp not matched",
+			spec:    &specVersionFile{VersionFile: ".tool-versions", VersionRegexp: `(?m)^helm\s+(\S+)`},
+			content: "nodejs 18.0.0\n",
+			wantErr: true,
+		},
+		{
+			name:    "toml",
+			spec:    &specVersionFile{VersionFile: "tools.toml", VersionPath: "tools.protoc.version"},
+			content: "[tools.protoc]\nversion = \"25.1\"\n",
+			want:    "25.1",
+		},
+		{
+			name:    "json array",
+			spec:    &specVersionFile{VersionFile: "tools.json", VersionPath: "tools.1.version"},
+			content: `{"tools":[{"version":"1.0"},{"version":"v1.28.0"}]}`,
+			want:    "v1.28.0",
+		},
+		{
+			name:    "yaml",
+			spec:    &specVersionFile{VersionFile: "tools.yaml", VersionPath: "kubectl"},
+			content: "kubectl: v1.28.0\n",
+			want:    "v1.28.0",
+		},
+		{
+			name:    "path not a value",
+			spec:    &specVersionFile{VersionFile: "tools.yaml", VersionPath: "tools"},
+			content: "tools:\n  kubectl: v1.28.0\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.spec.findVersion([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("findVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecVersionFile_useVersion(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	dir := t.TempDir()
+	writeExec(t, filepath.Join(dir, "1.5.7", "terraform"))
+	writeExec(t, filepath.Join(dir, "bin", "sh"))
+	s := &specVersionFile{VersionCmd: filepath.Join(dir, "{version}", "terraform"), binName: "terraform"}
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "1.5.7", want: filepath.Join(dir, "1.5.7", "terraform")},
+		{version: "1.6.0", want: "terraform"},
+		{version: "../bin/sh", wantErr: true},
+		{version: "..", wantErr: true},
+		{version: "1.5.7/../../bin/sh", wantErr: true},
+		{version: `1.5.7\x`, wantErr: true},
+		{version: "1.5.7 ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			r := &Rule{Cmd: "terraform"}
+			err := s.useVersion(r, tt.version, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("useVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && r.Cmd != tt.want {
+				t.Errorf("Cmd = %q, want %q", r.Cmd, tt.want)
+			}
+		})
+	}
+}
@@ -43,6 +43,8 @@ func Execute(args []string) {
 func setup() {
 	xattr.SetConfDir(configDir())
 	xattr.SetRootDir(filepath.Join(configDir(), "app_data"))
+	// DataDir 是基于 RootDir 的，需要在 SetRootDir 之后重新设置
+	xattr.SetDataDir("data")
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -60,6 +62,20 @@ func getApp(name string) string {
 
 func execute(ctx context.Context, name string, args []string) {
 	setLogPrefix("Load")
+	rule := loadRuleCache(name)
+	if rule == nil {
+		rule = resolveRule(ctx, name)
+	}
+	// 和 Spec、Pre、Post 不同，设置了 NoHook 时也检查，避免绕过锁文件
+	if err := rule.verifyLock(name); err != nil {
+		log.Fatalln("VerifyLock failed:", err)
+	}
+	os.Exit(rule.Run(ctx, args))
+}
+
+// resolveRule 加载配置，选出当前目录使用的规则，并执行 Spec，成功后写入规则缓存
+func resolveRule(ctx context.Context, name string) *Rule {
+	save := recordRuleCache(name)
 	cfg, err := LoadConfig(name)
 	if err != nil {
 		log.Fatalln("LoadConfig failed:", err)
@@ -71,5 +87,9 @@ func execute(ctx context.Context, name string, args []string) {
 	if err = rule.BeforeExec(ctx, name); err != nil {
 		log.Fatalln("BeforeExec failed:", err)
 	}
-	rule.Run(ctx, args)
+	if rule.lockFile, err = findLockFile(); err != nil {
+		log.Fatalln("Find lock file failed:", err)
+	}
+	save(rule)
+	return rule
 }
@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 
@@ -60,6 +61,22 @@ func init() {
 	homeDir = home
 }
 
+// expandHome 将以 "~" 开头的路径替换为用户的 home 目录
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+		return filepath.Join(homeDir, p[1:])
+	}
+	return p
+}
+
+func expandHomeAll(ps []string) []string {
+	result := make([]string, 0, len(ps))
+	for _, p := range ps {
+		result = append(result, expandHome(p))
+	}
+	return result
+}
+
 const envKeyPrefix = "BAS_"
 
 func envKey(name string) string {
@@ -128,6 +145,7 @@ func findFileUpper(name string, max int) (string, error) {
 	current := wd
 	for i := 0; i < max; i++ {
 		fp := filepath.Join(current, name)
+		watchPath(fp)
 		st, err1 := os.Stat(fp)
 		if err1 == nil && !st.IsDir() {
 			return fp, nil
@@ -141,6 +159,30 @@ func findFileUpper(name string, max int) (string, error) {
 	return "", fmt.Errorf("%w: %s", errFileNotFound, name)
 }
 
+// findFilesUpper 从当前目录开始，向上查找所有名为 name 的文件，越靠近当前目录的越靠前
+func findFilesUpper(name string, max int) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	current := wd
+	for i := 0; i < max; i++ {
+		fp := filepath.Join(current, name)
+		watchPath(fp)
+		st, err1 := os.Stat(fp)
+		if err1 == nil && !st.IsDir() {
+			result = append(result, fp)
+		}
+		next := filepath.Dir(current)
+		if next == current {
+			break
+		}
+		current = next
+	}
+	return result, nil
+}
+
 func parserGoModFile(fp string) (*modfile.File, error) {
 	content, err := os.ReadFile(fp)
 	if err != nil {
@@ -149,6 +191,28 @@ func parserGoModFile(fp string) (*modfile.File, error) {
 	return modfile.Parse(fp, content, nil)
 }
 
+// lookupEnv 查找环境变量的值，优先使用 env 中的(靠后的优先)，然后是 getenv
+func lookupEnv(env []string, key string) string {
+	for _, kv := range slices.Backward(env) {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && (k == key || (caseInsensitiveEnv && strings.EqualFold(k, key))) {
+			return v
+		}
+	}
+	return getenv(key)
+}
+
+// firstLine 返回内容中第一个非空、非注释('#'开头)的行
+func firstLine(content []byte) string {
+	for line := range strings.Lines(string(content)) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
 func disableHooks() bool {
 	// 环境变量 BAS_NoHook=true 或者 bas=off|no
 	return os.Getenv(envKey("NoHook")) != "" || os.Getenv("bas") == "off" || os.Getenv("bas") == "no"
@@ -0,0 +1,263 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/xanygo/anygo/cli/xcolor"
+	"github.com/xanygo/anygo/xcfg"
+
+	"github.com/fsgo/bin-auto-switcher/internal/actuator"
+)
+
+// validateConfigs 检查配置文件，name 为空时，检查所有的配置文件
+func validateConfigs(name string) error {
+	files, err := allConfigFiles(name)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("no config files found")
+		return nil
+	}
+	v := &validator{
+		done: map[string]bool{},
+	}
+	for _, f := range files {
+		v.checkFile(f.name, f.path, nil)
+	}
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %d problems", len(v.problems))
+}
+
+type configFile struct {
+	name string // 命令名称，如 go、git
+	path string
+}
+
+// allConfigFiles 查找全局和当前目录以及上级目录的配置文件
+func allConfigFiles(name string) ([]configFile, error) {
+	pattern := "*.toml"
+	if name != "" {
+		pattern = name + ".toml"
+	}
+	var dirs []string
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < 128; i++ {
+		dirs = append(dirs, filepath.Join(wd, ".bas"))
+		next := filepath.Dir(wd)
+		if next == wd {
+			break
+		}
+		wd = next
+	}
+	dirs = append(dirs, configDir())
+
+	var result []configFile
+	for _, dir := range slices.Backward(dirs) {
+		ms, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, fp := range ms {
+			if filepath.Base(fp) == lockFileName && dir != configDir() {
+				continue
+			}
+			result = append(result, configFile{
+				name: strings.TrimSuffix(filepath.Base(fp), ".toml"),
+				path: fp,
+			})
+		}
+	}
+	return skipIncluded(result), nil
+}
+
+// skipIncluded 去掉被其他配置文件 Include 的文件，如 .bas/go-shared.toml，
+// 其不是命令 go-shared 的配置，只在检查引入它的配置文件时一起检查。
+// 只被循环引入的文件，没有其他的入口，保留其中的第一个
+func skipIncluded(files []configFile) []configFile {
+	includes := make(map[string][]string, len(files))
+	included := make(map[string]bool)
+	for _, f := range files {
+		var cfg struct {
+			Include []string
+		}
+		// 解析失败的，在 checkFile 中报告
+		if err := xcfg.Parse(f.path, &cfg); err != nil {
+			continue
+		}
+		for _, inc := range cfg.Include {
+			ip := includePath(f.path, inc)
+			includes[f.path] = append(includes[f.path], ip)
+			if ip != f.path {
+				included[ip] = true
+			}
+		}
+	}
+
+	reached := make(map[string]bool)
+	var visit func(fp string)
+	visit = func(fp string) {
+		if reached[fp] {
+			return
+		}
+		reached[fp] = true
+		for _, ip := range includes[fp] {
+			visit(ip)
+		}
+	}
+	roots := make(map[string]bool)
+	for _, f := range files {
+		if !included[f.path] {
+			roots[f.path] = true
+			visit(f.path)
+		}
+	}
+	for _, f := range files {
+		if !reached[f.path] {
+			roots[f.path] = true
+			visit(f.path)
+		}
+	}
+	return slices.DeleteFunc(files, func(f configFile) bool {
+		return !roots[f.path]
+	})
+}
+
+type validator struct {
+	done     map[string]bool
+	problems []string
+}
+
+func (v *validator) report(fp string, field string, err error) {
+	msg := fp
+	if field != "" {
+		msg += ": " + field
+	}
+	msg += ": " + err.Error()
+	v.problems = append(v.problems, msg)
+	fmt.Println(xcolor.RedString("[Error]"), msg)
+}
+
+// checkFile 检查一个配置文件，以及其 Include 的配置文件
+func (v *validator) checkFile(name string, fp string, stack []string) {
+	fp, err := filepath.Abs(fp)
+	if err != nil {
+		v.report(fp, "", err)
+		return
+	}
+	if slices.Contains(stack, fp) {
+		v.report(fp, "Include", fmt.Errorf("include cycle detected: %s", strings.Join(append(stack, fp), " -> ")))
+		return
+	}
+	if v.done[fp] {
+		return
+	}
+	v.done[fp] = true
+	stack = append(stack, fp)
+
+	cfg := &Config{}
+	if err = xcfg.Parse(fp, &cfg); err != nil {
+		v.report(fp, "", err)
+		return
+	}
+	total := len(v.problems)
+	v.checkConfig(name, fp, cfg)
+
+	var includes []string
+	for idx, inc := range cfg.Include {
+		ip := includePath(fp, inc)
+		if ok, err := fileExists(ip); !ok {
+			if err == nil {
+				err = fmt.Errorf("%q not exists", ip)
+			}
+			v.report(fp, fmt.Sprintf("Include[%d]", idx), err)
+			continue
+		}
+		includes = append(includes, ip)
+	}
+
+	if total == len(v.problems) {
+		fmt.Println(xcolor.GreenString("[OK]   "), fp)
+	}
+	for _, ip := range includes {
+		v.checkFile(name, ip, stack)
+	}
+}
+
+func (v *validator) checkConfig(name string, fp string, cfg *Config) {
+	if err := checkSpec(name, cfg.Spec); err != nil {
+		v.report(fp, "Spec", err)
+	}
+	for ri, r := range cfg.Rules {
+		field := fmt.Sprintf("Rules[%d]", ri)
+		for di, dir := range r.Dir {
+			if strings.HasPrefix(dir, dirRegexpPrefix) || isGlobPattern(dir) {
+				if _, err := newDirPattern(dir); err != nil {
+					v.report(fp, fmt.Sprintf("%s.Dir[%d]", field, di), err)
+				}
+			}
+		}
+		for ci, c := range r.When {
+			if err := c.Check(); err != nil {
+				v.report(fp, fmt.Sprintf("%s.When[%d]", field, ci), err)
+			}
+		}
+		if err := checkSpec(name, r.Spec); err != nil {
+			v.report(fp, field+".Spec", err)
+		}
+		if r.Concurrency < 0 {
+			v.report(fp, field+".Concurrency", fmt.Errorf("invalid value %d", r.Concurrency))
+		}
+		if err := r.checkArgs(); err != nil {
+			v.report(fp, field, err)
+		}
+		v.checkCommands(fp, field, stagePre, r.Pre)
+		v.checkCommands(fp, field, stagePost, r.Post)
+	}
+}
+
+// checkCommands 检查规则 field 的 Pre 或者 Post(stage) 中的命令
+func (v *validator) checkCommands(fp string, field string, stage string, cmds []*Command) {
+	field += "." + stage
+	// Needs 中的 ID 可能在其他配置文件中
+	if err := checkNeeds(cmds, true); err != nil {
+		v.report(fp, field, err)
+	}
+	for idx, c := range cmds {
+		cf := fmt.Sprintf("%s[%d]", field, idx)
+		if c.Cmd == "" {
+			v.report(fp, cf+".Cmd", fmt.Errorf("is empty"))
+		} else if strings.HasPrefix(c.Cmd, actuator.Prefix) && !actuator.Exists(c.Cmd) {
+			v.report(fp, cf+".Cmd", fmt.Errorf("unknown inner command %q", c.Cmd))
+		}
+		if c.Match != "" {
+			if _, err := regexp.Compile(c.Match); err != nil {
+				v.report(fp, cf+".Match", err)
+			}
+		}
+		for ci, cd := range c.Cond {
+			if err := cd.Check(); err != nil {
+				v.report(fp, fmt.Sprintf("%s.Cond[%d]", cf, ci), err)
+			}
+		}
+		if err := c.checkOn(stage); err != nil {
+			v.report(fp, cf+".On", err)
+		}
+		if c.Timeout < 0 {
+			v.report(fp, cf+".Timeout", fmt.Errorf("invalid value %s", c.Timeout))
+		}
+	}
+}
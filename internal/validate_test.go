@@ -0,0 +1,183 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidator_checkFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // 期望的问题中包含的字段，为空表示没有问题
+	}{
+		{
+			name: "valid",
+			content: `
+[[Rules]]
+Cmd = "go"
+Dir = ["~/src/*/service", "regexp:^/home/.+/api/"]
+When = ["go_module"]
+[Rules.Spec]
+GoVersionFile = "go.mod"
+[[Rules.Pre]]
+ID = "fmt"
+Cmd = "gofmt"
+Match = "^build"
+[[Rules.Post]]
+Needs = ["fmt"]
+Cmd = "echo"
+On = "always"
+`,
+		},
+		{
+			name:    "bad Dir regexp",
+			content: "[[Rules]]\nDir = [\"regexp:(\"]\n",
+			want:    "Rules[0].Dir[0]",
+		},
+		{
+			name:    "unknown Spec",
+			content: "[[Rules]]\n[Rules.Spec]\nNotExists = 1\n",
+			want:    "Rules[0].Spec",
+		},
+		{
+			name:    "bad Needs",
+			content: "[[Rules]]\n[[Rules.Pre]]\nID = \"a\"\nNeeds = [\"a\"]\nCmd = \"echo\"\n",
+			want:    "Rules[0].Pre",
+		},
+		{
+			name:    "bad On",
+			content: "[[Rules]]\n[[Rules.Post]]\nOn = \"fail\"\nCmd = \"echo\"\n",
+			want:    "Rules[0].Post[0].On",
+		},
+		{
+			name:    "On in Pre",
+			content: "[[Rules]]\n[[Rules.Pre]]\nOn = \"failure\"\nCmd = \"echo\"\n",
+			want:    "Rules[0].Pre[0].On",
+		},
+		{
+			name:    "empty Cmd",
+			content: "[[Rules]]\n[[Rules.Pre]]\nMatch = \"^add\"\n",
+			want:    "Rules[0].Pre[0].Cmd",
+		},
+		{
+			name:    "bad Match",
+			content: "[[Rules]]\n[[Rules.Pre]]\nMatch = \"(\"\nCmd = \"echo\"\n",
+			want:    "Rules[0].Pre[0].Match",
+		},
+		{
+			name:    "include not exists",
+			content: "Include = [\"not_exists.toml\"]\n",
+			want:    "Include[0]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "go.toml")
+			writeFile(t, fp, tt.content)
+			v := &validator{done: map[string]bool{}}
+			v.checkFile("go", fp, nil)
+			if tt.want == "" {
+				if len(v.problems) != 0 {
+					t.Fatalf("problems = %q, want none", v.problems)
+				}
+				return
+			}
+			if len(v.problems) != 1 || !strings.Contains(v.problems[0], ": "+tt.want+": ") {
+				t.Fatalf("problems = %q, want one of %q", v.problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllConfigFiles(t *testing.T) {
+	home := t.TempDir()
+	setHomeDir(t, home)
+	project := t.TempDir()
+	sub := filepath.Join(project, "sub")
+	files := map[string]string{
+		filepath.Join(home, ".config", "bas", "git.toml"):        "[[Rules]]\n",
+		filepath.Join(project, ".bas", "go.toml"):                "Include = [\"go-shared.toml\"]\n[[Rules]]\n",
+		filepath.Join(project, ".bas", "go-shared.toml"):         "[Spec]\nGoVersionFile = \"go.mod\"\n",
+		filepath.Join(project, ".bas", lockFileName):             "",
+		filepath.Join(project, ".bas", "cycle-a.toml"):           "Include = [\"cycle-b.toml\"]\n",
+		filepath.Join(project, ".bas", "cycle-b.toml"):           "Include = [\"cycle-a.toml\"]\n",
+		filepath.Join(sub, ".bas", "node.toml"):                  "Include = [\"~/shared/node.toml\"]\n",
+		filepath.Join(home, "shared", "node.toml"):               "[[Rules]]\n",
+		filepath.Join(sub, ".bas", "shared", "not-globbed.toml"): "",
+	}
+	for fp, content := range files {
+		writeFile(t, fp, content)
+	}
+	t.Chdir(sub)
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{
+			name: "",
+			want: []string{
+				filepath.Join(home, ".config", "bas", "git.toml"),
+				filepath.Join(project, ".bas", "cycle-a.toml"),
+				filepath.Join(project, ".bas", "go.toml"),
+				filepath.Join(sub, ".bas", "node.toml"),
+			},
+		},
+		{
+			name: "go",
+			want: []string{filepath.Join(project, ".bas", "go.toml")},
+		},
+		{
+			name: "go-shared",
+			want: []string{filepath.Join(project, ".bas", "go-shared.toml")},
+		},
+		{
+			name: "java",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allConfigFiles(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for _, f := range got {
+				paths = append(paths, f.path)
+				if want := strings.TrimSuffix(filepath.Base(f.path), ".toml"); f.name != want {
+					t.Errorf("name = %q, want %q", f.name, want)
+				}
+			}
+			if !slices.Equal(paths, tt.want) {
+				t.Errorf("allConfigFiles(%q) = %q, want %q", tt.name, paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigs(t *testing.T) {
+	setHomeDir(t, t.TempDir())
+	project := t.TempDir()
+	t.Chdir(project)
+
+	// 被引入的文件按引入者的命令检查，GoVersionFile 是 go 的配置
+	writeFile(t, filepath.Join(project, ".bas", "go.toml"), "Include = [\"go-shared.toml\"]\n[[Rules]]\n")
+	writeFile(t, filepath.Join(project, ".bas", "go-shared.toml"), "[Spec]\nGoVersionFile = \"go.mod\"\n")
+	if err := validateConfigs(""); err != nil {
+		t.Fatalf("validateConfigs() = %v", err)
+	}
+
+	writeFile(t, filepath.Join(project, ".bas", "go-shared.toml"), "[Spec]\nNotExists = \"go.mod\"\n")
+	if err := validateConfigs(""); err == nil || err.Error() != "found 1 problems" {
+		t.Fatalf("validateConfigs() = %v, want 1 problem", err)
+	}
+	if err := validateConfigs("node"); err != nil {
+		t.Fatalf("validateConfigs(node) = %v", err)
+	}
+}
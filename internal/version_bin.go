@@ -0,0 +1,200 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// versionedBin 一个带版本号的可执行文件
+type versionedBin struct {
+	Path    string
+	Version semVersion
+
+	// InPath 是否是在 PATH 中找到的 {name}{version}，否则为安装目录中的
+	InPath bool
+}
+
+// versionResolver 在安装目录和 PATH 中查找满足版本约束的命令，用于各个 Spec
+type versionResolver struct {
+	// InstallDirs 安装目录，目录下的每个子目录为一个版本，如 ~/.nvm/versions/node 下的 v18.17.0
+	InstallDirs []string
+
+	// DirPrefix 安装目录下子目录名中版本号的前缀，可选，如 ~/sdk/go1.22.5 为 "go"
+	DirPrefix string
+
+	// Rels 可执行文件在版本目录中的位置，使用第一个存在的，如 bin/node
+	Rels []string
+
+	// PathPrefix 在 PATH 中查找名为 {PathPrefix}{version} 的命令，如 "go" 可以找到 go1.22.5，
+	// 为空时不在 PATH 中查找
+	PathPrefix string
+
+	// MinParts PATH 中命令的版本号至少包含的数字个数，如 python3 不能确定其具体的版本，需要 python3.12 这种的
+	MinParts int
+}
+
+// Candidates 所有找到的命令，安装目录中的在前
+func (vr *versionResolver) Candidates() []versionedBin {
+	var result []versionedBin
+	for _, dir := range vr.InstallDirs {
+		result = append(result, findInstalledVersions(dir, vr.DirPrefix, vr.Rels...)...)
+	}
+	if vr.PathPrefix == "" {
+		return result
+	}
+	for _, b := range findPathVersionedBins(vr.PathPrefix) {
+		if b.Version.Parts >= vr.MinParts {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// Resolve 查找满足约束的最高版本，安装目录中的优先，trace 为 true 时打印所有的候选
+func (vr *versionResolver) Resolve(vc versionConstraint, trace bool) (versionedBin, bool) {
+	all := vr.Candidates()
+	installed := slices.DeleteFunc(slices.Clone(all), func(b versionedBin) bool {
+		return b.InPath
+	})
+	b, ok := pickVersionedBin(installed, vc)
+	if !ok {
+		b, ok = pickVersionedBin(all, vc)
+	}
+	if trace {
+		log.Printf("found %d candidates in %q and PATH(%q):\n", len(all), vr.InstallDirs, vr.PathPrefix)
+		for _, c := range all {
+			mark := " "
+			if ok && c.Path == b.Path {
+				mark = "*"
+			} else if vc.Check(c.Version) {
+				mark = "+"
+			}
+			log.Printf("  %s %-12s %s\n", mark, c.Version, c.Path)
+		}
+	}
+	return b, ok
+}
+
+// findPathVersionedBins 在 PATH 中查找名为 {prefix}{version} 的可执行文件，如 go1.22.3、node18
+// 同名的文件，只使用 PATH 中靠前的
+func findPathVersionedBins(prefix string) []versionedBin {
+	var result []versionedBin
+	saw := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if isWindows() {
+				name = strings.TrimSuffix(name, ".exe")
+			}
+			after, ok := strings.CutPrefix(name, prefix)
+			if !ok || after == "" || after[0] < '0' || after[0] > '9' || saw[name] {
+				continue
+			}
+			v, ok := parseVersion(after)
+			if !ok {
+				continue
+			}
+			fp := filepath.Join(dir, e.Name())
+			if !isExecutable(fp) {
+				continue
+			}
+			saw[name] = true
+			result = append(result, versionedBin{Path: fp, Version: v, InPath: true})
+		}
+	}
+	return result
+}
+
+// findInstalledVersions 查找安装目录 root 下的各个版本，root 下的每个子目录名为 {dirPrefix}{version}，
+// 如 ~/.nvm/versions/node/v18.17.0，可执行文件的位置为 {root}/{version}/{rel}，rel 中可以包含多个候选，使用第一个存在的
+func findInstalledVersions(root string, dirPrefix string, rels ...string) []versionedBin {
+	watchPath(root)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var result []versionedBin
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), dirPrefix)
+		if !ok {
+			continue
+		}
+		v, ok := parseVersion(name)
+		if !ok {
+			continue
+		}
+		for _, rel := range rels {
+			fp := filepath.Join(root, e.Name(), rel)
+			if isExecutable(fp) {
+				result = append(result, versionedBin{Path: fp, Version: v})
+				break
+			}
+		}
+	}
+	return result
+}
+
+// useVersionedBin 使用找到的命令 b，安装目录中的，同时将其所在目录添加到 PATH 的最前面，
+// 让 npm 等脚本也使用对应版本的 node
+func useVersionedBin(r *Rule, name string, b versionedBin) {
+	r.Cmd = b.Path
+	if !b.InPath {
+		r.Env = prependPathEnv(r.Env, filepath.Dir(b.Path))
+	}
+	r.decide("using %s %s: %q", name, b.Version, b.Path)
+}
+
+// pickVersionedBin 从 bins 中选出满足约束的最高版本
+func pickVersionedBin(bins []versionedBin, vc versionConstraint) (versionedBin, bool) {
+	var best versionedBin
+	var found bool
+	for _, b := range bins {
+		if !vc.Check(b.Version) {
+			continue
+		}
+		if !found || b.Version.Compare(best.Version) > 0 {
+			best, found = b, true
+		}
+	}
+	return best, found
+}
+
+func isExecutable(fp string) bool {
+	if isWindows() && filepath.Ext(fp) == "" {
+		fp += ".exe"
+	}
+	watchPath(fp)
+	st, err := os.Stat(fp)
+	if err != nil || st.IsDir() {
+		return false
+	}
+	return isWindows() || st.Mode()&0111 != 0
+}
+
+// prependPathEnv 将 dir 添加到 PATH 环境变量的最前面，并放到 env 中，已有的 PATH 会被替换
+func prependPathEnv(env []string, dir string) []string {
+	name := "PATH"
+	if isWindows() {
+		name = "Path"
+	}
+	value := dir + string(os.PathListSeparator) + os.Getenv(name)
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.EqualFold(k, name) {
+			value = dir + string(os.PathListSeparator) + v
+		}
+	}
+	return dedupEnv(caseInsensitiveEnv, append(env, name+"="+value))
+}
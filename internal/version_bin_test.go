@@ -0,0 +1,70 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Date: 2026/10/18
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionResolver_Resolve(t *testing.T) {
+	if isWindows() {
+		t.Skip("not support windows")
+	}
+	sdk := t.TempDir()
+	pathDir := t.TempDir()
+	for _, fp := range []string{
+		filepath.Join(sdk, "go1.21.5", "bin", "go"),
+		filepath.Join(sdk, "go1.22.1", "bin", "go"),
+		filepath.Join(sdk, "other", "bin", "go"),
+		filepath.Join(pathDir, "go1.22.5"),
+		filepath.Join(pathDir, "go1.23.0"),
+		filepath.Join(pathDir, "go1"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fp, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", pathDir)
+
+	vr := &versionResolver{
+		InstallDirs: []string{sdk},
+		DirPrefix:   "go",
+		Rels:        []string{filepath.Join("bin", "go")},
+		PathPrefix:  "go",
+		MinParts:    2,
+	}
+	if got := len(vr.Candidates()); got != 4 {
+		t.Fatalf("Candidates() has %d, want 4", got)
+	}
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		// 安装目录中的优先
+		{constraint: "1.22", want: filepath.Join(sdk, "go1.22.1", "bin", "go")},
+		{constraint: ">=1.21 <1.23", want: filepath.Join(sdk, "go1.22.1", "bin", "go")},
+		{constraint: "~1.21", want: filepath.Join(sdk, "go1.21.5", "bin", "go")},
+		{constraint: ">=1.22.2", want: filepath.Join(pathDir, "go1.23.0")},
+		{constraint: "1.22.5", want: filepath.Join(pathDir, "go1.22.5")},
+		{constraint: "1.20"},
+	}
+	for _, tt := range tests {
+		vc, err := parseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, ok := vr.Resolve(vc, false)
+		if ok != (tt.want != "") || b.Path != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.constraint, b.Path, ok, tt.want)
+		}
+		if ok && b.InPath != (filepath.Dir(b.Path) == pathDir) {
+			t.Errorf("Resolve(%q).InPath = %v", tt.constraint, b.InPath)
+		}
+	}
+}